@@ -4,10 +4,10 @@ import (
 	"context"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/SlamJam/go-libs/options"
 	"github.com/SlamJam/go-libs/xgo"
-	"github.com/SlamJam/go-libs/xsync"
 	"github.com/pkg/errors"
 )
 
@@ -19,20 +19,12 @@ var (
 type actorOptions struct {
 	onInterrupt func(context.Context) error
 	onHalt      func(context.Context, error)
-}
-
-type PanicHandler func(any)
-
-var globalPanicHandler xsync.Value[PanicHandler]
-
-func SetGlobalPanicHandler(h PanicHandler) {
-	globalPanicHandler.Store(h)
-}
-
-func callGlobalPanicHandler(p any) {
-	if h, ok := globalPanicHandler.Load(); ok {
-		h(p)
-	}
+	restartable bool
+	name        string
+	onPanic     PanicHandler
+	panicPolicy PanicPolicy
+	// Пауза перед перезапуском по PanicPolicyRestart
+	restartBackoff restartBackoff
 }
 
 // Жизненный цикл:
@@ -52,15 +44,55 @@ type Actor interface {
 	Error() error
 }
 
-type actor struct {
-	inited bool
+// Restartable реализуют акторы, которые ведут номер запуска, например, созданные NewActor.
+// Проверяется приведением типа: a.(actors.Restartable).
+type Restartable interface {
+	// Generation возвращает номер текущего запуска: 0 до первого Start,
+	// далее увеличивается на каждый (пере)запуск.
+	Generation() uint64
+}
+
+// StatProvider реализуют акторы со встроенной статистикой, например, созданные NewActor.
+// Проверяется приведением типа: a.(actors.StatProvider).
+type StatProvider interface {
+	Stat() ActorStat
+}
 
+// actorRun - состояние одного запуска актора.
+type actorRun struct {
 	startOnce  *sync.Once
 	cancelOnce *sync.Once
 	cancelFunc atomic.Pointer[context.CancelFunc]
 	done       chan struct{}
 	started    chan struct{}
 	haltError  error
+}
+
+func newActorRun() *actorRun {
+	return &actorRun{
+		startOnce:  &sync.Once{},
+		cancelOnce: &sync.Once{},
+		started:    make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+}
+
+func (r *actorRun) isHalted() bool {
+	select {
+	case <-r.done:
+		return true
+	default:
+		return false
+	}
+}
+
+type actor struct {
+	inited bool
+
+	runMu      sync.Mutex
+	run        atomic.Pointer[actorRun]
+	generation atomic.Uint64
+	counters   actorStatCounters
 
 	main func(context.Context) error
 	opts actorOptions
@@ -80,6 +112,21 @@ func WithOnHalt(f func(context.Context, error)) Opt {
 	}
 }
 
+// WithName задаёт имя актора, которое попадает в PanicReport.
+func WithName(name string) Opt {
+	return func(o *actorOptions) {
+		o.name = name
+	}
+}
+
+// WithRestartable позволяет запускать актор повторно после его остановки.
+// Каждый запуск получает новый контекст и увеличивает Generation.
+func WithRestartable(restartable bool) Opt {
+	return func(o *actorOptions) {
+		o.restartable = restartable
+	}
+}
+
 func NewActor(main func(context.Context) error, opts ...Opt) Actor {
 	return newActor(main, opts...)
 }
@@ -88,19 +135,22 @@ func newActor(main func(context.Context) error, opts ...Opt) *actor {
 	c := &actor{
 		inited: true,
 
-		startOnce:  &sync.Once{},
-		cancelOnce: &sync.Once{},
-		started:    make(chan struct{}),
-		done:       make(chan struct{}),
-
 		main: main,
 	}
 
+	c.run.Store(newActorRun())
+
+	c.opts.restartBackoff = restartBackoff{initial: defaultRestartBackoff, max: defaultMaxRestartBackoff}
 	options.ApplyInto(&c.opts, opts...)
 
 	return c
 }
 
+var (
+	_ Restartable  = (*actor)(nil)
+	_ StatProvider = (*actor)(nil)
+)
+
 func (c *actor) mustInitialized() {
 	if c == nil {
 		panic("actor is nil")
@@ -114,25 +164,55 @@ func (c *actor) mustInitialized() {
 func (c *actor) Start(ctx context.Context) (result bool) {
 	c.mustInitialized()
 
-	c.startOnce.Do(func() {
+	c.runMu.Lock()
+	defer c.runMu.Unlock()
+
+	r := c.run.Load()
+	if c.opts.restartable && r.isHalted() {
+		r = newActorRun()
+		c.run.Store(r)
+	}
+
+	r.startOnce.Do(func() {
+		c.generation.Add(1)
+
 		go func() {
-			lctx, cancel := context.WithCancel(ctx)
-			c.cancelFunc.Store(&cancel)
+			lctx, cancel := context.WithCancel(context.WithValue(ctx, actorCtxKey{}, c))
+			r.cancelFunc.Store(&cancel)
+			c.counters.onStart()
 
 			defer func() {
 				cancel()
-				c.cancelFunc.Store(nil)
-				close(c.done)
+				r.cancelFunc.Store(nil)
+				close(r.done)
 			}()
 
-			close(c.started)
+			close(r.started)
+
+			var delay time.Duration
+
+			for {
+				runStarted := time.Now()
+				r.haltError = xgo.CatchPanicInErr(func() error {
+					return errors.WithStack(c.main(lctx))
+				})
+				c.counters.onHalt(r.haltError)
+
+				if !c.handlePanic(lctx, r.haltError) {
+					break
+				}
 
-			c.haltError = xgo.CatchPanicInErr(func() error {
-				return errors.WithStack(c.main(lctx))
-			})
+				delay = c.opts.restartBackoff.next(delay, time.Since(runStarted))
+				if !waitRestart(lctx, delay) {
+					break
+				}
+
+				c.generation.Add(1)
+				c.counters.onStart()
+			}
 
 			if hndl := c.opts.onHalt; hndl != nil {
-				hndl(lctx, c.haltError)
+				hndl(lctx, r.haltError)
 			}
 		}()
 
@@ -149,12 +229,13 @@ func (c *actor) Interrupt(ctx context.Context) (err error) {
 
 	c.mustInitialized()
 
-	if cancel := c.cancelFunc.Load(); cancel == nil {
+	r := c.run.Load()
+	if cancel := r.cancelFunc.Load(); cancel == nil {
 		err = ErrActorIsNotRunning
 	} else {
-		c.cancelOnce.Do(func() {
+		r.cancelOnce.Do(func() {
 			(*cancel)()
-			c.cancelFunc.Store(nil)
+			r.cancelFunc.Store(nil)
 
 			if hndl := c.opts.onInterrupt; hndl != nil {
 				err = hndl(ctx)
@@ -166,25 +247,20 @@ func (c *actor) Interrupt(ctx context.Context) (err error) {
 }
 
 func (c *actor) IsInterrupted() bool {
-	return c.cancelFunc.Load() == nil
+	return c.run.Load().cancelFunc.Load() == nil
 }
 
 func (c *actor) IsHalted() bool {
 	c.mustInitialized()
 
-	select {
-	case <-c.done:
-		return true
-	default:
-		return false
-	}
+	return c.run.Load().isHalted()
 }
 
 func (c *actor) IsStarted() bool {
 	c.mustInitialized()
 
 	select {
-	case <-c.started:
+	case <-c.run.Load().started:
 		return true
 	default:
 		return false
@@ -201,7 +277,7 @@ func (c *actor) WaitUntilStarted(ctx context.Context) error {
 	c.mustInitialized()
 
 	select {
-	case <-c.started:
+	case <-c.run.Load().started:
 		return nil
 	case <-ctx.Done():
 		return ctx.Err()
@@ -212,7 +288,7 @@ func (c *actor) WaitUntilHalted(ctx context.Context) error {
 	c.mustInitialized()
 
 	select {
-	case <-c.done:
+	case <-c.run.Load().done:
 		return nil
 	case <-ctx.Done():
 		return ctx.Err()
@@ -220,5 +296,14 @@ func (c *actor) WaitUntilHalted(ctx context.Context) error {
 }
 
 func (c *actor) Error() error {
-	return c.haltError
+	r := c.run.Load()
+	if !r.isHalted() {
+		return nil
+	}
+
+	return r.haltError
+}
+
+func (c *actor) Generation() uint64 {
+	return c.generation.Load()
 }
@@ -0,0 +1,98 @@
+package actors
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/SlamJam/go-libs/xgo"
+	"github.com/pkg/errors"
+)
+
+// ActorStat - счётчики, которые ведёт каждый актор.
+type ActorStat struct {
+	// Время старта и остановки последнего запуска
+	StartedAt time.Time
+	HaltedAt  time.Time
+	Uptime    time.Duration
+
+	Restarts  uint64
+	Panics    uint64
+	Processed uint64
+
+	// Последняя ненулевая ошибка завершения среди всех запусков
+	LastError error
+}
+
+type actorStatCounters struct {
+	startedAt atomic.Int64
+	haltedAt  atomic.Int64
+	panics    atomic.Uint64
+	processed atomic.Uint64
+	lastError atomic.Pointer[error]
+}
+
+func (sc *actorStatCounters) onStart() {
+	sc.startedAt.Store(time.Now().UnixNano())
+	sc.haltedAt.Store(0)
+}
+
+func (sc *actorStatCounters) onHalt(err error) {
+	sc.haltedAt.Store(time.Now().UnixNano())
+
+	if err == nil {
+		return
+	}
+
+	sc.lastError.Store(&err)
+
+	var panicErr xgo.PanicError
+	if errors.As(err, &panicErr) {
+		sc.panics.Add(1)
+	}
+}
+
+func unixNanoToTime(ns int64) time.Time {
+	if ns == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(0, ns)
+}
+
+func (c *actor) Stat() ActorStat {
+	st := ActorStat{
+		StartedAt: unixNanoToTime(c.counters.startedAt.Load()),
+		HaltedAt:  unixNanoToTime(c.counters.haltedAt.Load()),
+		Panics:    c.counters.panics.Load(),
+		Processed: c.counters.processed.Load(),
+	}
+
+	if gen := c.generation.Load(); gen > 1 {
+		st.Restarts = gen - 1
+	}
+
+	if err := c.counters.lastError.Load(); err != nil {
+		st.LastError = *err
+	}
+
+	switch {
+	case st.StartedAt.IsZero():
+	case st.HaltedAt.IsZero():
+		st.Uptime = time.Since(st.StartedAt)
+	default:
+		st.Uptime = st.HaltedAt.Sub(st.StartedAt)
+	}
+
+	return st
+}
+
+type actorCtxKey struct{}
+
+// AddProcessed увеличивает счётчик обработанных сообщений актора, которому принадлежит ctx.
+// ctx должен быть получен из контекста, переданного в main актора; иначе вызов ничего не делает.
+func AddProcessed(ctx context.Context, n uint64) {
+	if c, ok := ctx.Value(actorCtxKey{}).(*actor); ok {
+		c.counters.processed.Add(n)
+	}
+}
@@ -3,6 +3,8 @@ package actors_test
 import (
 	"context"
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/SlamJam/go-libs/actors"
@@ -77,3 +79,145 @@ func TestOwnActor(t *testing.T) {
 	assert.Equal(t, true, c.Finished)
 	assert.Equal(t, true, c.IsHalted())
 }
+
+func TestRestartableActor(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var err error
+	var runs atomic.Int32
+
+	c := actors.NewActor(
+		func(ctx context.Context) error {
+			runs.Add(1)
+			return nil
+		},
+		actors.WithRestartable(true),
+	)
+
+	assert.Equal(t, uint64(0), c.(actors.Restartable).Generation())
+
+	for i := range 3 {
+		assert.True(t, c.Start(ctx))
+
+		err = c.WaitUntilHalted(ctx)
+		assert.NoError(t, err)
+
+		assert.Equal(t, uint64(i+1), c.(actors.Restartable).Generation())
+	}
+
+	assert.Equal(t, int32(3), runs.Load())
+}
+
+func TestNonRestartableActor(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c := actors.NewActor(
+		func(ctx context.Context) error {
+			return nil
+		},
+	)
+
+	assert.True(t, c.Start(ctx))
+
+	err := c.WaitUntilHalted(ctx)
+	assert.NoError(t, err)
+
+	assert.False(t, c.Start(ctx))
+	assert.Equal(t, uint64(1), c.(actors.Restartable).Generation())
+}
+
+func TestActorStat(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var err error
+
+	c := actors.NewActor(
+		func(ctx context.Context) error {
+			actors.AddProcessed(ctx, 2)
+			panic("boom")
+		},
+		actors.WithRestartable(true),
+	)
+
+	assert.Equal(t, actors.ActorStat{}, c.(actors.StatProvider).Stat())
+
+	for range 2 {
+		c.Start(ctx)
+		err = c.WaitUntilHalted(ctx)
+		assert.NoError(t, err)
+	}
+
+	stat := c.(actors.StatProvider).Stat()
+	assert.Equal(t, uint64(1), stat.Restarts)
+	assert.Equal(t, uint64(2), stat.Panics)
+	assert.Equal(t, uint64(4), stat.Processed)
+	assert.Error(t, stat.LastError)
+	assert.False(t, stat.StartedAt.IsZero())
+	assert.False(t, stat.HaltedAt.Before(stat.StartedAt))
+}
+
+type counters struct {
+	Hits uint64
+}
+
+func TestActorWithStatModify(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c := actors.NewActorWithStat(
+		func(ctx context.Context, st actors.StatUpdater[counters]) error {
+			var wg sync.WaitGroup
+			for range 100 {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					st.Modify(func(c *counters) { c.Hits++ })
+				}()
+			}
+			wg.Wait()
+
+			return nil
+		},
+	)
+
+	c.Start(ctx)
+	err := c.WaitUntilHalted(ctx)
+	assert.NoError(t, err)
+
+	assert.Equal(t, uint64(100), c.GetStat().Hits)
+
+	c.ViewStat(func(c *counters) {
+		assert.Equal(t, uint64(100), c.Hits)
+	})
+}
+
+func TestActorWithStatUpdate(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c := actors.NewActorWithStat(
+		func(ctx context.Context, st actors.StatUpdater[counters]) error {
+			st.Update(counters{Hits: 7})
+			return nil
+		},
+	)
+
+	c.Start(ctx)
+	err := c.WaitUntilHalted(ctx)
+	assert.NoError(t, err)
+
+	assert.Equal(t, counters{Hits: 7}, c.GetStat())
+}
@@ -2,13 +2,14 @@ package actors
 
 import (
 	"context"
-	"sync/atomic"
+	"sync"
 )
 
 type ActorWithStat[T any] interface {
 	Actor
 
 	GetStat() T
+	ViewStat(f func(*T))
 }
 
 type actorStat[T any] struct {
@@ -18,40 +19,69 @@ type actorStat[T any] struct {
 }
 
 type StatReader[T any] interface {
+	// GetCurrent возвращает копию текущего значения.
 	GetCurrent() T
+	// View даёт прочитать отдельные поля, не копируя значение целиком. f не должна сохранять указатель.
+	View(f func(*T))
 }
 
 type StatUpdater[T any] interface {
 	StatReader[T]
 
+	// Update заменяет значение целиком.
 	Update(T)
+	// Modify атомарно изменяет значение на месте, например, увеличивает счётчики.
+	Modify(f func(*T))
 }
 
 type statUpdater[T any] struct {
-	p atomic.Pointer[T]
+	mu sync.RWMutex
+	v  T
 }
 
-func (st *statUpdater[T]) GetCurrent() T {
-	return *st.p.Load()
+func (st *statUpdater[T]) GetCurrent() (v T) {
+	st.View(func(cur *T) {
+		v = *cur
+	})
+
+	return v
+}
+
+func (st *statUpdater[T]) View(f func(*T)) {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+
+	f(&st.v)
 }
 
 func (st *statUpdater[T]) Update(v T) {
-	st.p.Store(&v)
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	st.v = v
+}
+
+func (st *statUpdater[T]) Modify(f func(*T)) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	f(&st.v)
 }
 
 func NewActorWithStat[T any](main func(context.Context, StatUpdater[T]) error, opts ...Opt) ActorWithStat[T] {
-	a := actorStat[T]{}
+	a := &actorStat[T]{}
 
 	a.actor = *newActor(func(ctx context.Context) error {
 		return main(ctx, &a.stat)
 	}, opts...)
 
-	var t T
-	a.stat.p.Store(&t)
-
-	return &a
+	return a
 }
 
 func (a *actorStat[T]) GetStat() T {
 	return a.stat.GetCurrent()
 }
+
+func (a *actorStat[T]) ViewStat(f func(*T)) {
+	a.stat.View(f)
+}
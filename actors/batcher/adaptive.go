@@ -0,0 +1,158 @@
+package batcher
+
+import (
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/SlamJam/go-libs/actors"
+)
+
+// adaptiveAlpha - вес нового замера в скользящих средних.
+const adaptiveAlpha = 0.2
+
+// AdaptiveFlush - настройки подбора интервала отправки под нагрузку.
+type AdaptiveFlush struct {
+	// Границы интервала. Min не меньше миллисекунды
+	Min time.Duration
+	Max time.Duration
+	// Ждать, пока наберётся столько item. По умолчанию размер batch.
+	// Если при текущем темпе batch не наберётся и за Max, ждать нет смысла, и интервал равен Min
+	TargetSize int
+	// Item должен уйти из batcher не позже, чем через LatencySLO, с учётом времени отправки batch
+	LatencySLO time.Duration
+}
+
+// WithAdaptiveFlush подбирает интервал отправки между Min и Max по темпу поступления item
+// и времени отправки batch, вместо фиксированного WithFlushInterval.
+// Интервал отсчитывается от первого item batch; текущее значение видно в Stat.
+func WithAdaptiveFlush(cfg AdaptiveFlush) Opt {
+	return func(o *batcherOptions) {
+		o.adaptive = &cfg
+	}
+}
+
+// Stat - статистика batcher.
+type Stat struct {
+	// Интервал, с которым будет отправлен следующий batch
+	FlushInterval time.Duration
+	// Оценка темпа поступления item в секунду. Считается только с WithAdaptiveFlush
+	ArrivalRate float64
+	// Оценка времени отправки batch потребителю. Считается только с WithAdaptiveFlush
+	FlushLatency time.Duration
+	// Item, потерянные при остановке
+	Dropped int
+}
+
+var _ actors.ActorWithStat[Stat] = (*Batcher[int])(nil)
+
+// flushTuner подбирает интервал отправки. Замеры делает только do, читать результат можно откуда угодно.
+type flushTuner struct {
+	cfg AdaptiveFlush
+
+	lastArrival time.Time
+	// Скользящие средние в секундах
+	gap     float64
+	latency float64
+
+	interval  atomic.Int64
+	rate      atomic.Uint64
+	latencyNs atomic.Int64
+}
+
+func newFlushTuner(cfg AdaptiveFlush, size int) *flushTuner {
+	if cfg.TargetSize <= 0 {
+		cfg.TargetSize = size
+	}
+
+	// Нулевой интервал означал бы, что batch не отправляется по времени вовсе
+	cfg.Min = max(cfg.Min, time.Millisecond)
+	cfg.Max = max(cfg.Max, cfg.Min)
+
+	t := &flushTuner{cfg: cfg}
+	t.interval.Store(int64(cfg.Min))
+
+	return t
+}
+
+func ewma(avg, sample float64) float64 {
+	if avg == 0 {
+		return sample
+	}
+
+	return avg + adaptiveAlpha*(sample-avg)
+}
+
+func (t *flushTuner) arrived(now time.Time) {
+	if !t.lastArrival.IsZero() {
+		t.gap = ewma(t.gap, now.Sub(t.lastArrival).Seconds())
+		if t.gap > 0 {
+			t.rate.Store(math.Float64bits(1 / t.gap))
+		}
+	}
+
+	t.lastArrival = now
+}
+
+func (t *flushTuner) flushed(d time.Duration) {
+	t.latency = ewma(t.latency, d.Seconds())
+	t.latencyNs.Store(int64(t.latency * float64(time.Second)))
+}
+
+// next возвращает интервал для batch, в котором уже buffered item.
+func (t *flushTuner) next(buffered int) time.Duration {
+	d := t.cfg.Max
+
+	if t.cfg.TargetSize > 0 {
+		missing := t.cfg.TargetSize - buffered
+
+		switch {
+		case missing <= 0:
+			d = t.cfg.Min
+		case t.gap == 0:
+			// Темп ещё неизвестен
+			d = t.cfg.Min
+		default:
+			fill := time.Duration(t.gap * float64(missing) * float64(time.Second))
+			if fill > t.cfg.Max {
+				d = t.cfg.Min
+			} else {
+				d = fill
+			}
+		}
+	}
+
+	if t.cfg.LatencySLO > 0 {
+		d = min(d, t.cfg.LatencySLO-time.Duration(t.latency*float64(time.Second)))
+	}
+
+	d = min(max(d, t.cfg.Min), t.cfg.Max)
+	t.interval.Store(int64(d))
+
+	return d
+}
+
+func (t *flushTuner) fill(st *Stat) {
+	st.FlushInterval = time.Duration(t.interval.Load())
+	st.ArrivalRate = math.Float64frombits(t.rate.Load())
+	st.FlushLatency = time.Duration(t.latencyNs.Load())
+}
+
+// GetStat возвращает снимок статистики batcher.
+func (b *Batcher[T]) GetStat() Stat {
+	st := Stat{
+		FlushInterval: b.flushAfter,
+		Dropped:       b.Dropped(),
+	}
+
+	if b.tuner != nil {
+		b.tuner.fill(&st)
+	}
+
+	return st
+}
+
+func (b *Batcher[T]) ViewStat(f func(*Stat)) {
+	st := b.GetStat()
+	f(&st)
+}
@@ -0,0 +1,67 @@
+package batcher_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/SlamJam/go-libs/actors/batcher"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatcherAdaptiveFlush(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	b := batcher.NewBatcher[int](100, batcher.WithOutputBuffered(100), batcher.WithAdaptiveFlush(batcher.AdaptiveFlush{
+		Min: 5 * time.Millisecond,
+		Max: time.Second,
+	}))
+
+	b.Start(ctx)
+
+	// Одиночный item не ждёт Max: batch всё равно не наберётся
+	start := time.Now()
+	assert.NoError(t, b.Add(ctx, 1))
+	assert.Equal(t, [][]int{{1}}, collect(t, b.C(), 1))
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+	assert.Equal(t, 5*time.Millisecond, b.GetStat().FlushInterval)
+
+	// При плотном потоке интервал растёт, чтобы набрать batch
+	for i := range 50 {
+		assert.NoError(t, b.Add(ctx, i))
+		time.Sleep(time.Millisecond)
+	}
+
+	b.ViewStat(func(st *batcher.Stat) {
+		assert.Greater(t, st.FlushInterval, 5*time.Millisecond)
+		assert.Greater(t, st.ArrivalRate, 1.0)
+	})
+}
+
+func TestBatcherAdaptiveFlushSLO(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	b := batcher.NewBatcher[int](0, batcher.WithOutputBuffered(10), batcher.WithAdaptiveFlush(batcher.AdaptiveFlush{
+		Min:        time.Millisecond,
+		Max:        time.Second,
+		LatencySLO: 30 * time.Millisecond,
+	}))
+
+	b.Start(ctx)
+
+	start := time.Now()
+	assert.NoError(t, b.Add(ctx, 1))
+	assert.NoError(t, b.Add(ctx, 2))
+	assert.Equal(t, [][]int{{1, 2}}, collect(t, b.C(), 1))
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+
+	st := b.GetStat()
+	assert.LessOrEqual(t, st.FlushInterval, 30*time.Millisecond)
+	assert.Greater(t, st.FlushInterval, time.Millisecond)
+}
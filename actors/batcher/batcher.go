@@ -2,6 +2,7 @@ package batcher
 
 import (
 	"context"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -9,32 +10,55 @@ import (
 	"github.com/SlamJam/go-libs/options"
 	"github.com/SlamJam/go-libs/xchan"
 	"github.com/SlamJam/go-libs/xslices"
+	"github.com/pkg/errors"
 )
 
+var ErrClosed = errors.New("batcher is closed")
+
+// DefaultShutdownTimeout - сколько по умолчанию ждать отправки оставшихся batch при отмене ctx.
+const DefaultShutdownTimeout = time.Second
+
 type Batcher[T any] struct {
 	actors.Actor
+	lifecycle
 
-	reuseBuf   bool
-	flushCap   int
+	limits     limits[T]
 	flushAfter time.Duration
+	tuner      *flushTuner
 	outCh      chan []T
 	InputCh    chan T
 	flushed    atomic.Bool
+	pool       *sync.Pool
+	leaseCh    chan Batch[T]
+
+	// Вызывается для batch, потерянных при остановке
+	onDrop func([]T)
 }
 
 type batcherOptions struct {
 	inCap      int
 	outCap     int
-	reuseBuf   bool
 	flushAfter time.Duration
+	adaptive   *AdaptiveFlush
+
+	weight      any
+	maxWeight   int
+	oversized   OversizedPolicy
+	onOversized any
+
+	shutdownTimeout time.Duration
+	pooled          bool
+	maxKeys         int
 }
 
 type Opt = options.Opt[batcherOptions]
 
+// WithReuseBuffer ничего не делает: каждый batch в C() - новый слайс.
+// Раньше буфер переиспользовался, пока потребитель ещё мог читать прошлый batch.
+//
+// Deprecated: используйте WithPooledBatches.
 func WithReuseBuffer(reuse bool) Opt {
-	return func(o *batcherOptions) {
-		o.reuseBuf = reuse
-	}
+	return func(o *batcherOptions) {}
 }
 
 func WithInputBuffered(size int) Opt {
@@ -55,18 +79,44 @@ func WithFlushInterval(interval time.Duration) Opt {
 	}
 }
 
+// WithShutdownTimeout задаёт, сколько ждать отправки оставшихся batch, когда ctx актора отменён.
+// По умолчанию DefaultShutdownTimeout.
+func WithShutdownTimeout(d time.Duration) Opt {
+	return func(o *batcherOptions) {
+		o.shutdownTimeout = d
+	}
+}
+
+// NewBatcher создаёт batcher, который отдаёт batch, как только в нём набралось size item
+// или вес достиг предела WithMaxWeight. size <= 0 - без ограничения числа item.
 func NewBatcher[T any](size int, opts ...Opt) *Batcher[T] {
-	var optState batcherOptions
+	optState := batcherOptions{shutdownTimeout: DefaultShutdownTimeout}
 
 	options.ApplyInto(&optState, opts...)
 
 	b := &Batcher[T]{
-		reuseBuf:   optState.reuseBuf,
+		limits:     newLimits[T](size, optState),
 		flushAfter: optState.flushAfter,
 		InputCh:    make(chan T, optState.inCap),
 		outCh:      make(chan []T, optState.outCap),
 	}
 
+	if optState.adaptive != nil {
+		b.tuner = newFlushTuner(*optState.adaptive, size)
+	}
+
+	if optState.pooled {
+		b.pool = newBatchPool[T]()
+		b.leaseCh = make(chan Batch[T], optState.outCap)
+	}
+
+	b.lifecycle.init(optState.shutdownTimeout, func() {
+		close(b.outCh)
+		if b.leaseCh != nil {
+			close(b.leaseCh)
+		}
+	})
+
 	b.Actor = actors.NewActor(b.do)
 
 	return b
@@ -81,42 +131,127 @@ func (b *Batcher[T]) IsFlushed() bool {
 }
 
 func (b *Batcher[T]) do(ctx context.Context) error {
-	buf := make([]T, 0, b.flushCap)
+	if !b.begin() {
+		return ErrClosed
+	}
+
+	buf := batchBuffer[T]{items: make([]T, 0, max(b.limits.count, 0))}
+
+	out := outlet[T, []T]{
+		l:      &b.lifecycle,
+		input:  b.InputCh,
+		send:   b.send,
+		size:   func(batch []T) int { return len(batch) },
+		keep:   b.keep,
+		onDrop: b.onDrop,
+	}
+
+	emit := func(batch []T) {
+		start := time.Now()
+		if out.emit(ctx, batch) && b.tuner != nil {
+			b.tuner.flushed(time.Since(start))
+		}
+	}
 
 	var timer <-chan time.Time
-	for {
+	for !out.stalled() {
 		var flush bool
 
 		select {
 		case item := <-b.InputCh:
-			if len(buf) == 0 {
-				if b.flushAfter != 0 {
-					timer = time.After(b.flushAfter)
-				}
-				b.flushed.Store(false)
+			if b.tuner != nil {
+				b.tuner.arrived(time.Now())
 			}
 
-			buf = append(buf, item)
-			flush = len(buf) >= b.flushCap
+			flush = b.limits.add(&buf, item, func() {
+				b.flush(&buf, emit)
+				timer = nil
+			}, emit)
 		case <-timer:
 			flush = true
 		case <-ctx.Done():
-			return ctx.Err()
+		case <-b.stop:
 		}
 
+		// Полный buf отправляем и при остановке, иначе drain дополнит его следующими item
 		if flush {
-			b.outCh <- buf
-			if b.reuseBuf {
-				// Dangerous
-				buf = buf[:]
-			} else {
-				buf = xslices.NewWithSameTypeAndCap(buf)
-			}
+			b.flush(&buf, emit)
+		}
+
+		if ctx.Err() != nil || b.isStopped() {
+			break
+		}
 
+		switch {
+		case len(buf.items) == 0:
 			timer = nil
 			b.flushed.Store(true)
+		case timer == nil:
+			// Интервал отсчитывается от первого item batch
+			if d := b.flushInterval(len(buf.items)); d != 0 {
+				timer = time.After(d)
+			}
+			b.flushed.Store(false)
 		}
 	}
+
+	return out.drain(ctx, func(e func([]T)) {
+		emit = e
+	}, func(item T) {
+		if b.limits.add(&buf, item, func() { b.flush(&buf, emit) }, emit) {
+			b.flush(&buf, emit)
+		}
+	}, func() {
+		b.flush(&buf, emit)
+		b.flushed.Store(true)
+	})
+}
+
+func (b *Batcher[T]) flushInterval(buffered int) time.Duration {
+	if b.tuner != nil {
+		return b.tuner.next(buffered)
+	}
+
+	return b.flushAfter
+}
+
+// Add отправляет item в batcher. После Shutdown возвращает ErrClosed.
+func (b *Batcher[T]) Add(ctx context.Context, item T) error {
+	return addTo(ctx, &b.lifecycle, b.InputCh, item)
+}
+
+// Shutdown прекращает приём item, отправляет накопленное и закрывает C().
+// Если ctx истёк раньше, чем удалось отправить всё, возвращает число потерянных item и ошибку ctx.
+// Item, отправленные напрямую в InputCh после Shutdown, не читаются.
+func (b *Batcher[T]) Shutdown(ctx context.Context) (dropped int, err error) {
+	return b.shutdown(ctx, b.IsStarted, func() int {
+		var rest []T
+		for range len(b.InputCh) {
+			rest = append(rest, <-b.InputCh)
+		}
+
+		if b.onDrop != nil && len(rest) > 0 {
+			b.onDrop(rest)
+		}
+
+		return len(rest)
+	})
+}
+
+func (b *Batcher[T]) flush(buf *batchBuffer[T], emit func([]T)) {
+	if len(buf.items) == 0 {
+		return
+	}
+
+	emit(buf.items)
+
+	if b.reusesBuffer() {
+		buf.items = buf.items[:0]
+	} else {
+		buf.items = xslices.NewWithSameTypeAndCap(buf.items)
+	}
+
+	buf.weight = 0
 }
 
 func Foo() {
@@ -0,0 +1,194 @@
+package batcher_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/SlamJam/go-libs/actors/batcher"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatcherSize(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	b := batcher.NewBatcher[int](3, batcher.WithOutputBuffered(4))
+	b.Start(ctx)
+
+	for i := range 7 {
+		b.InputCh <- i
+	}
+
+	assert.Equal(t, [][]int{{0, 1, 2}, {3, 4, 5}}, collect(t, b.C(), 2))
+	assert.False(t, b.IsFlushed())
+}
+
+func TestBatcherWeight(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var dropped []string
+
+	b := batcher.NewBatcher[string](10,
+		batcher.WithMaxWeight(func(s string) int { return len(s) }, 5, batcher.OversizedAlone),
+		batcher.WithFlushInterval(20*time.Millisecond),
+		batcher.WithOutputBuffered(8),
+	)
+	b.Start(ctx)
+
+	for _, s := range []string{"ab", "cd", "ef", "toolong", "g", "hijkl"} {
+		b.InputCh <- s
+	}
+
+	assert.Equal(t, [][]string{{"ab", "cd"}, {"ef"}, {"toolong"}, {"g"}, {"hijkl"}}, collect(t, b.C(), 5))
+
+	d := batcher.NewBatcher[string](10,
+		batcher.WithMaxWeight(func(s string) int { return len(s) }, 5, batcher.OversizedDrop),
+		batcher.WithOversizedHandler(func(s string) { dropped = append(dropped, s) }),
+		batcher.WithFlushInterval(20*time.Millisecond),
+		batcher.WithOutputBuffered(8),
+	)
+	d.Start(ctx)
+
+	for _, s := range []string{"ab", "toolong", "cd"} {
+		d.InputCh <- s
+	}
+
+	assert.Equal(t, [][]string{{"ab", "cd"}}, collect(t, d.C(), 1))
+	assert.Equal(t, []string{"toolong"}, dropped)
+}
+
+func TestBatcherWeightTypeMismatch(t *testing.T) {
+	t.Parallel()
+
+	assert.Panics(t, func() {
+		batcher.NewBatcher[string](10, batcher.WithMaxWeight(func(i int) int { return i }, 5, batcher.OversizedAlone))
+	})
+
+	assert.Panics(t, func() {
+		batcher.NewBatcher[string](10, batcher.WithOversizedHandler(func(i int) {}))
+	})
+}
+
+func TestBatcherShutdown(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	b := batcher.NewBatcher[int](3, batcher.WithInputBuffered(8))
+	b.Start(ctx)
+	assert.NoError(t, b.WaitUntilStarted(ctx))
+
+	var got [][]int
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for batch := range b.C() {
+			got = append(got, batch)
+		}
+	}()
+
+	for i := range 5 {
+		assert.NoError(t, b.Add(ctx, i))
+	}
+
+	dropped, err := b.Shutdown(ctx)
+	assert.NoError(t, err)
+	assert.Zero(t, dropped)
+
+	<-done
+	assert.Equal(t, [][]int{{0, 1, 2}, {3, 4}}, got)
+
+	assert.ErrorIs(t, b.Add(ctx, 5), batcher.ErrClosed)
+	assert.NoError(t, b.WaitUntilHalted(ctx))
+}
+
+func TestBatcherShutdownDeadline(t *testing.T) {
+	t.Parallel()
+
+	b := batcher.NewBatcher[int](2, batcher.WithInputBuffered(8))
+	b.Start(context.Background())
+	assert.NoError(t, b.WaitUntilStarted(context.Background()))
+
+	for i := range 5 {
+		assert.NoError(t, b.Add(context.Background(), i))
+	}
+
+	// Никто не читает C(), поэтому ничего не отправится
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	dropped, err := b.Shutdown(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 5, dropped)
+
+	_, ok := <-b.C()
+	assert.False(t, ok)
+}
+
+func TestBatcherInterruptFlushes(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	b := batcher.NewBatcher[int](10, batcher.WithOutputBuffered(1))
+	b.Start(ctx)
+
+	b.InputCh <- 1
+	b.InputCh <- 2
+
+	cancel()
+
+	assert.Equal(t, [][]int{{1, 2}}, collect(t, b.C(), 1))
+
+	_, ok := <-b.C()
+	assert.False(t, ok)
+	assert.Zero(t, b.Dropped())
+}
+
+func TestBatcherInterruptRacingAdd(t *testing.T) {
+	t.Parallel()
+
+	for range 20 {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		b := batcher.NewBatcher[int](4, batcher.WithInputBuffered(64), batcher.WithOutputBuffered(64))
+		b.Start(ctx)
+
+		var accepted atomic.Int64
+		var wg sync.WaitGroup
+		for i := range 8 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				for j := range 50 {
+					if b.Add(context.Background(), i*100+j) != nil {
+						return
+					}
+					accepted.Add(1)
+				}
+			}()
+		}
+
+		time.Sleep(time.Millisecond)
+		cancel()
+
+		delivered := 0
+		for batch := range b.C() {
+			delivered += len(batch)
+		}
+
+		wg.Wait()
+
+		// Каждый принятый item либо отправлен, либо учтён как потерянный
+		assert.Equal(t, int(accepted.Load()), delivered+b.Dropped())
+	}
+}
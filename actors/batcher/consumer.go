@@ -0,0 +1,212 @@
+package batcher
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/SlamJam/go-libs/actors"
+	"github.com/SlamJam/go-libs/xgo"
+)
+
+// ConsumerConfig описывает обработку batch из Batcher.
+type ConsumerConfig[T any] struct {
+	// Сколько batch обрабатывается одновременно. По умолчанию 1
+	Workers int
+	// Сколько batch может быть взято из Batcher, но ещё не завершено, включая ждущих своей очереди на commit.
+	// Когда предел достигнут, Consumer перестаёт читать batch, и Batcher перестаёт читать InputCh.
+	// По умолчанию Workers
+	MaxInFlight int
+	// Вызывать OnCommit и OnFail строго в порядке batch, даже если они обработаны в другом порядке
+	Ordered bool
+
+	// Сколько раз повторить batch, обработчик которого вернул ошибку (nack)
+	Retries      int
+	RetryBackoff time.Duration
+
+	// Вызывается для batch, обработанного без ошибки (ack)
+	OnCommit func([]T)
+	// Вызывается для batch, все попытки которого закончились ошибкой
+	OnFail func([]T, error)
+}
+
+// Consumer обрабатывает batch из Batcher несколькими обработчиками.
+// Batcher запускается и останавливается отдельно; Consumer завершается, когда Batcher закрывает выход.
+type Consumer[T any] struct {
+	actors.Actor
+
+	src    *Batcher[T]
+	handle func(context.Context, []T) error
+	cfg    ConsumerConfig[T]
+}
+
+type consumed[T any] struct {
+	seq   uint64
+	items []T
+	lease *Batch[T]
+	err   error
+}
+
+// NewConsumer создаёт Consumer. Ошибка или паника handle означает nack, nil - ack.
+func NewConsumer[T any](src *Batcher[T], handle func(context.Context, []T) error, cfg ConsumerConfig[T]) *Consumer[T] {
+	cfg.Workers = max(cfg.Workers, 1)
+	cfg.MaxInFlight = max(cfg.MaxInFlight, cfg.Workers)
+
+	c := &Consumer[T]{
+		src:    src,
+		handle: handle,
+		cfg:    cfg,
+	}
+
+	c.Actor = actors.NewActor(c.do)
+
+	return c
+}
+
+func (c *Consumer[T]) do(ctx context.Context) error {
+	jobs := make(chan *consumed[T])
+	results := make(chan *consumed[T])
+	slots := make(chan struct{}, c.cfg.MaxInFlight)
+
+	var wg sync.WaitGroup
+	for range c.cfg.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for j := range jobs {
+				j.err = c.process(ctx, j.items)
+				results <- j
+			}
+		}()
+	}
+
+	committed := make(chan struct{})
+	go func() {
+		defer close(committed)
+		c.commit(results, slots)
+	}()
+
+	var seq uint64
+	for {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+
+		if ctx.Err() != nil {
+			break
+		}
+
+		j, ok := c.next(ctx)
+		if !ok {
+			<-slots
+			break
+		}
+
+		j.seq = seq
+		seq++
+		jobs <- j
+	}
+
+	close(jobs)
+	wg.Wait()
+	close(results)
+	<-committed
+
+	return ctx.Err()
+}
+
+func (c *Consumer[T]) next(ctx context.Context) (*consumed[T], bool) {
+	if c.src.pool != nil {
+		select {
+		case lease, ok := <-c.src.Batches():
+			if !ok {
+				return nil, false
+			}
+
+			return &consumed[T]{items: lease.Items(), lease: &lease}, true
+		case <-ctx.Done():
+			return nil, false
+		}
+	}
+
+	select {
+	case items, ok := <-c.src.C():
+		if !ok {
+			return nil, false
+		}
+
+		return &consumed[T]{items: items}, true
+	case <-ctx.Done():
+		return nil, false
+	}
+}
+
+// process вызывает обработчик, повторяя его после nack до Retries раз, пока не отменён ctx.
+func (c *Consumer[T]) process(ctx context.Context, items []T) error {
+	for attempt := 0; ; attempt++ {
+		err := xgo.CatchPanicInErr(func() error {
+			return c.handle(ctx, items)
+		})
+
+		if err == nil || attempt >= c.cfg.Retries || ctx.Err() != nil {
+			return err
+		}
+
+		if c.cfg.RetryBackoff > 0 {
+			timer := time.NewTimer(c.cfg.RetryBackoff)
+
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return err
+			}
+		}
+	}
+}
+
+// commit завершает обработанные batch и освобождает их места, при Ordered - в порядке batch.
+func (c *Consumer[T]) commit(results <-chan *consumed[T], slots <-chan struct{}) {
+	var next uint64
+	waiting := map[uint64]*consumed[T]{}
+
+	for r := range results {
+		if !c.cfg.Ordered {
+			c.finish(r)
+			<-slots
+
+			continue
+		}
+
+		waiting[r.seq] = r
+
+		for {
+			r, ok := waiting[next]
+			if !ok {
+				break
+			}
+
+			delete(waiting, next)
+			next++
+
+			c.finish(r)
+			<-slots
+		}
+	}
+}
+
+func (c *Consumer[T]) finish(r *consumed[T]) {
+	if r.err == nil {
+		if c.cfg.OnCommit != nil {
+			c.cfg.OnCommit(r.items)
+		}
+	} else if c.cfg.OnFail != nil {
+		c.cfg.OnFail(r.items, r.err)
+	}
+
+	if r.lease != nil {
+		r.lease.Release()
+	}
+}
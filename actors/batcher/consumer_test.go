@@ -0,0 +1,121 @@
+package batcher_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/SlamJam/go-libs/actors/batcher"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsumerOrderedRetry(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	b := batcher.NewBatcher[int](1, batcher.WithInputBuffered(10))
+
+	var attempts atomic.Int32
+	var mu sync.Mutex
+	var committed [][]int
+	var failed [][]int
+
+	c := batcher.NewConsumer(b, func(ctx context.Context, batch []int) error {
+		switch batch[0] {
+		case 0:
+			// Первый batch завершается последним
+			time.Sleep(30 * time.Millisecond)
+		case 1:
+			if attempts.Add(1) < 3 {
+				return errors.New("nack")
+			}
+		case 2:
+			return errors.New("always")
+		}
+
+		return nil
+	}, batcher.ConsumerConfig[int]{
+		Workers: 3,
+		Ordered: true,
+		Retries: 2,
+		OnCommit: func(batch []int) {
+			mu.Lock()
+			committed = append(committed, batch)
+			mu.Unlock()
+		},
+		OnFail: func(batch []int, err error) {
+			mu.Lock()
+			failed = append(failed, batch)
+			committed = append(committed, nil)
+			mu.Unlock()
+		},
+	})
+
+	b.Start(ctx)
+	c.Start(ctx)
+	assert.NoError(t, b.WaitUntilStarted(ctx))
+
+	for i := range 4 {
+		assert.NoError(t, b.Add(ctx, i))
+	}
+
+	_, err := b.Shutdown(ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, c.WaitUntilHalted(ctx))
+
+	assert.Equal(t, [][]int{{0}, {1}, nil, {3}}, committed)
+	assert.Equal(t, [][]int{{2}}, failed)
+	assert.Equal(t, int32(3), attempts.Load())
+}
+
+func TestConsumerMaxInFlight(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	b := batcher.NewBatcher[int](1)
+
+	release := make(chan struct{})
+	var active, peak atomic.Int32
+
+	c := batcher.NewConsumer(b, func(ctx context.Context, batch []int) error {
+		n := active.Add(1)
+		defer active.Add(-1)
+
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+
+		<-release
+		return nil
+	}, batcher.ConsumerConfig[int]{Workers: 2})
+
+	b.Start(ctx)
+	c.Start(ctx)
+
+	assert.NoError(t, b.Add(ctx, 1))
+	assert.NoError(t, b.Add(ctx, 2))
+
+	// Оба места заняты: третий batch ждёт в batcher, и InputCh перестаёт читаться
+	assert.NoError(t, b.Add(ctx, 3))
+
+	addCtx, addCancel := context.WithTimeout(ctx, 30*time.Millisecond)
+	defer addCancel()
+	assert.ErrorIs(t, b.Add(addCtx, 4), context.DeadlineExceeded)
+
+	close(release)
+
+	_, err := b.Shutdown(ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, c.WaitUntilHalted(ctx))
+	assert.Equal(t, int32(2), peak.Load())
+}
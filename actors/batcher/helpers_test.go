@@ -0,0 +1,42 @@
+package batcher_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/SlamJam/go-libs/actors/batcher"
+)
+
+// collect читает из ch n batch, ожидая каждый не дольше секунды.
+func collect[B any](t *testing.T, ch <-chan B, n int) []B {
+	t.Helper()
+
+	var result []B
+	for range n {
+		select {
+		case batch := <-ch:
+			result = append(result, batch)
+		case <-time.After(time.Second):
+			t.Fatalf("expected %d batches, got %d", n, len(result))
+		}
+	}
+
+	return result
+}
+
+// collectKeyed читает n batch и группирует id событий по ключу.
+func collectKeyed(t *testing.T, ch <-chan batcher.KeyedBatch[string, event], n int) map[string][][]int {
+	t.Helper()
+
+	result := map[string][][]int{}
+	for _, batch := range collect(t, ch, n) {
+		var ids []int
+		for _, e := range batch.Items {
+			ids = append(ids, e.id)
+		}
+
+		result[batch.Key] = append(result[batch.Key], ids)
+	}
+
+	return result
+}
@@ -0,0 +1,257 @@
+package batcher
+
+import (
+	"container/list"
+	"context"
+	"time"
+
+	"github.com/SlamJam/go-libs/actors"
+	"github.com/SlamJam/go-libs/options"
+)
+
+// KeyedBatch - batch item с одним ключом.
+type KeyedBatch[K comparable, T any] struct {
+	Key   K
+	Items []T
+}
+
+// WithMaxKeys ограничивает число ключей, для которых KeyedBatcher копит item.
+// Когда приходит item нового ключа сверх предела, отправляется batch ключа, дольше всех не получавшего item.
+func WithMaxKeys(n int) Opt {
+	return func(o *batcherOptions) {
+		o.maxKeys = n
+	}
+}
+
+// KeyedBatcher собирает item в отдельные batch по ключу. Ограничения размера, веса и интервала
+// действуют для каждого ключа отдельно. WithPooledBatches и WithAdaptiveFlush не поддерживаются.
+type KeyedBatcher[K comparable, T any] struct {
+	actors.Actor
+	lifecycle
+
+	key        func(T) K
+	limits     limits[T]
+	flushAfter time.Duration
+	maxKeys    int
+	InputCh    chan T
+	outCh      chan KeyedBatch[K, T]
+}
+
+// NewKeyedBatcher создаёт batcher, который группирует item по key(item).
+func NewKeyedBatcher[K comparable, T any](key func(T) K, size int, opts ...Opt) *KeyedBatcher[K, T] {
+	optState := batcherOptions{shutdownTimeout: DefaultShutdownTimeout}
+
+	options.ApplyInto(&optState, opts...)
+
+	b := &KeyedBatcher[K, T]{
+		key:        key,
+		limits:     newLimits[T](size, optState),
+		flushAfter: optState.flushAfter,
+		maxKeys:    optState.maxKeys,
+		InputCh:    make(chan T, optState.inCap),
+		outCh:      make(chan KeyedBatch[K, T], optState.outCap),
+	}
+
+	b.lifecycle.init(optState.shutdownTimeout, func() {
+		close(b.outCh)
+	})
+
+	b.Actor = actors.NewActor(b.do)
+
+	return b
+}
+
+func (b *KeyedBatcher[K, T]) C() <-chan KeyedBatch[K, T] {
+	return b.outCh
+}
+
+type keyBuffer[K comparable, T any] struct {
+	key      K
+	buf      batchBuffer[T]
+	deadline time.Time
+	// Элементы списков keyedBuffers.lru и keyedBuffers.deadlines
+	used    *list.Element
+	expires *list.Element
+}
+
+// keyedBuffers - открытые ключи, то есть ключи с накопленными item.
+type keyedBuffers[K comparable, T any] struct {
+	b    *KeyedBatcher[K, T]
+	open map[K]*keyBuffer[K, T]
+	// В начале - ключ, получивший item последним
+	lru *list.List
+	// Интервал у всех ключей одинаковый, поэтому порядок открытия совпадает с порядком сроков
+	deadlines *list.List
+	emit      func(KeyedBatch[K, T])
+}
+
+func (b *KeyedBatcher[K, T]) newBuffers() *keyedBuffers[K, T] {
+	return &keyedBuffers[K, T]{
+		b:         b,
+		open:      map[K]*keyBuffer[K, T]{},
+		lru:       list.New(),
+		deadlines: list.New(),
+	}
+}
+
+func (s *keyedBuffers[K, T]) add(item T) {
+	k := s.b.key(item)
+
+	kb, ok := s.open[k]
+	if ok {
+		s.lru.MoveToFront(kb.used)
+	} else {
+		if s.b.maxKeys > 0 && len(s.open) >= s.b.maxKeys {
+			s.close(s.lru.Back().Value.(*keyBuffer[K, T]))
+		}
+
+		kb = &keyBuffer[K, T]{key: k}
+		kb.used = s.lru.PushFront(kb)
+		s.open[k] = kb
+	}
+
+	emit := func(items []T) {
+		s.emit(KeyedBatch[K, T]{Key: k, Items: items})
+	}
+
+	if s.b.limits.add(&kb.buf, item, func() { s.flush(kb) }, emit) {
+		s.flush(kb)
+	}
+
+	switch {
+	case len(kb.buf.items) == 0:
+		s.close(kb)
+	case kb.expires == nil && s.b.flushAfter != 0:
+		// Интервал отсчитывается от первого item batch
+		kb.deadline = time.Now().Add(s.b.flushAfter)
+		kb.expires = s.deadlines.PushBack(kb)
+	}
+}
+
+// flush отправляет накопленное по ключу, ключ остаётся открытым.
+func (s *keyedBuffers[K, T]) flush(kb *keyBuffer[K, T]) {
+	if len(kb.buf.items) > 0 {
+		s.emit(KeyedBatch[K, T]{Key: kb.key, Items: kb.buf.items})
+	}
+
+	kb.buf = batchBuffer[T]{}
+
+	if kb.expires != nil {
+		s.deadlines.Remove(kb.expires)
+		kb.expires = nil
+	}
+}
+
+// close отправляет накопленное по ключу и закрывает ключ.
+func (s *keyedBuffers[K, T]) close(kb *keyBuffer[K, T]) {
+	s.flush(kb)
+	s.lru.Remove(kb.used)
+	delete(s.open, kb.key)
+}
+
+// expire закрывает ключи, срок которых наступил.
+func (s *keyedBuffers[K, T]) expire(now time.Time) {
+	for e := s.deadlines.Front(); e != nil; e = s.deadlines.Front() {
+		kb := e.Value.(*keyBuffer[K, T])
+		if kb.deadline.After(now) {
+			return
+		}
+
+		s.close(kb)
+	}
+}
+
+// nextDeadline возвращает ближайший срок или false, если ждать нечего.
+func (s *keyedBuffers[K, T]) nextDeadline() (time.Time, bool) {
+	e := s.deadlines.Front()
+	if e == nil {
+		return time.Time{}, false
+	}
+
+	return e.Value.(*keyBuffer[K, T]).deadline, true
+}
+
+// closeAll закрывает ключи, начиная с дольше всех не получавшего item.
+func (s *keyedBuffers[K, T]) closeAll() {
+	for e := s.lru.Back(); e != nil; e = s.lru.Back() {
+		s.close(e.Value.(*keyBuffer[K, T]))
+	}
+}
+
+func (b *KeyedBatcher[K, T]) do(ctx context.Context) error {
+	if !b.begin() {
+		return ErrClosed
+	}
+
+	bufs := b.newBuffers()
+
+	out := outlet[T, KeyedBatch[K, T]]{
+		l:     &b.lifecycle,
+		input: b.InputCh,
+		send:  b.send,
+		size:  func(batch KeyedBatch[K, T]) int { return len(batch.Items) },
+	}
+	bufs.emit = func(batch KeyedBatch[K, T]) {
+		out.emit(ctx, batch)
+	}
+
+	timer := time.NewTimer(0)
+	<-timer.C
+	defer timer.Stop()
+
+	var armed time.Time
+	for !out.stalled() {
+		if deadline, ok := bufs.nextDeadline(); ok && !deadline.Equal(armed) {
+			timer.Stop()
+			timer.Reset(time.Until(deadline))
+			armed = deadline
+		}
+
+		select {
+		case item := <-b.InputCh:
+			bufs.add(item)
+		case now := <-timer.C:
+			armed = time.Time{}
+			bufs.expire(now)
+		case <-ctx.Done():
+		case <-b.stop:
+		}
+
+		if ctx.Err() != nil || b.isStopped() {
+			break
+		}
+	}
+
+	return out.drain(ctx, func(emit func(KeyedBatch[K, T])) {
+		bufs.emit = emit
+	}, bufs.add, bufs.closeAll)
+}
+
+func (b *KeyedBatcher[K, T]) send(batch KeyedBatch[K, T], stop1, stop2 <-chan struct{}) bool {
+	select {
+	case b.outCh <- batch:
+		return true
+	case <-stop1:
+	case <-stop2:
+	}
+
+	return false
+}
+
+// Add отправляет item в batcher. После Shutdown возвращает ErrClosed.
+func (b *KeyedBatcher[K, T]) Add(ctx context.Context, item T) error {
+	return addTo(ctx, &b.lifecycle, b.InputCh, item)
+}
+
+// Shutdown прекращает приём item, отправляет накопленное по всем ключам и закрывает C().
+// Если ctx истёк раньше, чем удалось отправить всё, возвращает число потерянных item и ошибку ctx.
+func (b *KeyedBatcher[K, T]) Shutdown(ctx context.Context) (dropped int, err error) {
+	return b.shutdown(ctx, b.IsStarted, func() int {
+		n := len(b.InputCh)
+		for range n {
+			<-b.InputCh
+		}
+
+		return n
+	})
+}
@@ -0,0 +1,66 @@
+package batcher_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/SlamJam/go-libs/actors/batcher"
+	"github.com/stretchr/testify/assert"
+)
+
+type event struct {
+	tenant string
+	id     int
+}
+
+func tenantOf(e event) string {
+	return e.tenant
+}
+
+func TestKeyedBatcher(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	b := batcher.NewKeyedBatcher(tenantOf, 2, batcher.WithOutputBuffered(10), batcher.WithFlushInterval(30*time.Millisecond))
+
+	b.Start(ctx)
+
+	for i, tenant := range []string{"a", "b", "a", "b", "a"} {
+		assert.NoError(t, b.Add(ctx, event{tenant: tenant, id: i}))
+	}
+
+	assert.Equal(t, map[string][][]int{"a": {{0, 2}}, "b": {{1, 3}}}, collectKeyed(t, b.C(), 2))
+
+	// Неполный batch уходит по интервалу
+	assert.Equal(t, map[string][][]int{"a": {{4}}}, collectKeyed(t, b.C(), 1))
+}
+
+func TestKeyedBatcherMaxKeys(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	b := batcher.NewKeyedBatcher(tenantOf, 10, batcher.WithOutputBuffered(10), batcher.WithMaxKeys(2))
+
+	b.Start(ctx)
+
+	for i, tenant := range []string{"a", "b", "a", "c"} {
+		assert.NoError(t, b.Add(ctx, event{tenant: tenant, id: i}))
+	}
+
+	// "b" дольше всех не получал item и вытеснен ключом "c"
+	assert.Equal(t, map[string][][]int{"b": {{1}}}, collectKeyed(t, b.C(), 1))
+
+	dropped, err := b.Shutdown(ctx)
+	assert.NoError(t, err)
+	assert.Zero(t, dropped)
+
+	assert.Equal(t, map[string][][]int{"a": {{0, 2}}, "c": {{3}}}, collectKeyed(t, b.C(), 2))
+
+	_, ok := <-b.C()
+	assert.False(t, ok)
+}
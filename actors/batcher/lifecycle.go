@@ -0,0 +1,245 @@
+package batcher
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/SlamJam/go-libs/xsync"
+)
+
+// lifecycle - общая часть batcher, отвечающая за остановку: приём Add, Shutdown и закрытие выхода.
+type lifecycle struct {
+	shutdownTimeout time.Duration
+	// Закрывается в начале Shutdown: Add больше не принимает item
+	closing   chan struct{}
+	closeOnce sync.Once
+	// Shutdown берёт на запись, чтобы дождаться Add, успевших пройти проверку
+	addMu sync.RWMutex
+	// Закрывается, когда do пора завершаться
+	stop        chan struct{}
+	shutdownCtx context.Context
+	// Закрывается после закрытия выхода
+	finished chan struct{}
+	outOnce  sync.Once
+	closeOut func()
+	dropped  atomic.Int64
+	// Защищает выбор, кто закрывает выход: do или Shutdown до старта
+	runMu   sync.Mutex
+	running bool
+}
+
+func (l *lifecycle) init(shutdownTimeout time.Duration, closeOut func()) {
+	l.shutdownTimeout = shutdownTimeout
+	l.closing = make(chan struct{})
+	l.stop = make(chan struct{})
+	l.finished = make(chan struct{})
+	l.closeOut = closeOut
+}
+
+// begin вызывается в начале do и возвращает false, если batcher уже закрыт.
+func (l *lifecycle) begin() bool {
+	l.runMu.Lock()
+	defer l.runMu.Unlock()
+
+	l.running = !l.isClosed()
+
+	return l.running
+}
+
+// finishContext возвращает ctx, с которым do отправляет остатки, и ошибку, которую do вернёт.
+func (l *lifecycle) finishContext(ctx context.Context) (context.Context, context.CancelFunc, error) {
+	if l.isStopped() {
+		return l.shutdownCtx, func() {}, nil
+	}
+
+	finishCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), l.shutdownTimeout)
+
+	return finishCtx, cancel, ctx.Err()
+}
+
+func (l *lifecycle) isStopped() bool {
+	select {
+	case <-l.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+func (l *lifecycle) isClosed() bool {
+	select {
+	case <-l.finished:
+		return true
+	default:
+		return false
+	}
+}
+
+func (l *lifecycle) closeOutput() {
+	l.outOnce.Do(func() {
+		l.closeOut()
+		close(l.finished)
+	})
+}
+
+// seal прекращает приём item и дожидается Add, успевших пройти проверку до этого.
+// После seal во вход больше ничего не попадёт, и его можно дочитать целиком.
+func (l *lifecycle) seal() {
+	l.closeOnce.Do(func() {
+		close(l.closing)
+	})
+
+	xsync.Barrier(&l.addMu)
+}
+
+// done запоминает число потерянных item и закрывает выход.
+func (l *lifecycle) done(dropped int) {
+	l.dropped.Store(int64(dropped))
+	l.closeOutput()
+}
+
+// Dropped возвращает число item, потерянных при остановке.
+func (l *lifecycle) Dropped() int {
+	return int(l.dropped.Load())
+}
+
+// outlet отправляет batch типа B на выход batcher с входом типа T.
+// Пока do работает, batch, не отправленные из-за остановки, копятся в pending, а drain отправляет
+// их вместе с остатками при завершении.
+type outlet[T, B any] struct {
+	l     *lifecycle
+	input chan T
+	// send отправляет batch, пока не закрыт stop1 или stop2. Возвращает false, если не удалось
+	send func(batch B, stop1, stop2 <-chan struct{}) bool
+	size func(batch B) int
+	// Вызывается для batch, попадающего в pending; может быть nil
+	keep func(batch B) B
+	// Вызывается для batch, потерянных при остановке; может быть nil
+	onDrop func(batch B)
+
+	// Batch, которые не удалось отправить из-за остановки; их отправит drain
+	pending []B
+}
+
+// emit отправляет batch, пока не отменён ctx и не вызван Shutdown. Возвращает false, если batch отложен.
+// После первого отложенного batch откладываются и все следующие, чтобы не нарушить порядок.
+func (o *outlet[T, B]) emit(ctx context.Context, batch B) bool {
+	if len(o.pending) == 0 && o.send(batch, ctx.Done(), o.l.stop) {
+		return true
+	}
+
+	if o.keep != nil {
+		batch = o.keep(batch)
+	}
+	o.pending = append(o.pending, batch)
+
+	return false
+}
+
+// stalled возвращает true, если есть отложенные batch и do пора завершаться.
+func (o *outlet[T, B]) stalled() bool {
+	return len(o.pending) > 0
+}
+
+// drain завершает do: отправляет pending, item, уже стоящие во входе, и накопленное, пока не истёк
+// ctx завершения, считает то, что отправить не успели, и закрывает выход. Возвращает ошибку для do.
+// redirect переключает отправку batch на переданный emit, add добавляет item, flush отправляет накопленное.
+func (o *outlet[T, B]) drain(ctx context.Context, redirect func(emit func(B)), add func(item T), flush func()) error {
+	finishCtx, cancel, err := o.l.finishContext(ctx)
+	defer cancel()
+
+	// После отмены ctx актора Add, пришедший во время дочитывания входа, иначе потерялся бы без учёта
+	o.l.seal()
+
+	var dropped int
+	emit := func(batch B) {
+		if finishCtx.Err() == nil && o.send(batch, finishCtx.Done(), nil) {
+			return
+		}
+
+		dropped += o.size(batch)
+		if o.onDrop != nil {
+			o.onDrop(batch)
+		}
+	}
+
+	redirect(emit)
+
+	for _, batch := range o.pending {
+		emit(batch)
+	}
+	o.pending = nil
+
+	// Отправители через вход могут писать бесконечно, поэтому забираем только то, что уже в буфере канала
+	for range len(o.input) {
+		add(<-o.input)
+	}
+
+	flush()
+
+	o.l.done(dropped)
+
+	return err
+}
+
+func addTo[T any](ctx context.Context, l *lifecycle, ch chan<- T, item T) error {
+	l.addMu.RLock()
+	defer l.addMu.RUnlock()
+
+	select {
+	case <-l.closing:
+		return ErrClosed
+	default:
+	}
+
+	select {
+	case ch <- item:
+		return nil
+	case <-l.closing:
+		return ErrClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// shutdown останавливает batcher. Если актор ещё не стартовал, выход закрывается здесь,
+// а item, уже стоящие во входе, отбрасывает dropInput и возвращает их число.
+func (l *lifecycle) shutdown(ctx context.Context, started func() bool, dropInput func() int) (int, error) {
+	first := false
+	l.closeOnce.Do(func() {
+		first = true
+		close(l.closing)
+	})
+
+	if !first {
+		return 0, ErrClosed
+	}
+
+	l.seal()
+
+	l.shutdownCtx = ctx
+	close(l.stop)
+
+	// Если актор уже стартовал, do обязательно запустится и закроет выход сам
+	l.runMu.Lock()
+	if !l.running && !started() {
+		l.dropped.Store(int64(dropInput()))
+		l.closeOutput()
+	}
+	l.runMu.Unlock()
+
+	select {
+	case <-l.finished:
+	case <-ctx.Done():
+		// do отправляет с тем же ctx и вот-вот закончит
+		<-l.finished
+	}
+
+	if n := l.Dropped(); n > 0 {
+		return n, ctx.Err()
+	}
+
+	return 0, nil
+}
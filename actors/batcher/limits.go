@@ -0,0 +1,95 @@
+package batcher
+
+import "github.com/SlamJam/go-libs/options"
+
+// OversizedPolicy определяет, что делать с item, который один тяжелее WithMaxWeight.
+type OversizedPolicy int
+
+const (
+	// OversizedAlone отправляет item отдельным batch из одного элемента.
+	OversizedAlone OversizedPolicy = iota
+	// OversizedDrop отбрасывает item и передаёт его обработчику WithOversizedHandler.
+	OversizedDrop
+)
+
+// WithMaxWeight ограничивает суммарный вес batch: batch отправляется, как только следующий item
+// не поместился бы в maxWeight или вес достиг его. Тип T должен совпадать с типом item batcher.
+func WithMaxWeight[T any](weight func(T) int, maxWeight int, oversized OversizedPolicy) Opt {
+	return func(o *batcherOptions) {
+		o.weight = weight
+		o.maxWeight = maxWeight
+		o.oversized = oversized
+	}
+}
+
+// WithOversizedHandler задаёт обработчик item, отброшенных политикой OversizedDrop.
+func WithOversizedHandler[T any](h func(T)) Opt {
+	return func(o *batcherOptions) {
+		o.onOversized = h
+	}
+}
+
+type limits[T any] struct {
+	count       int
+	weight      func(T) int
+	maxWeight   int
+	oversized   OversizedPolicy
+	onOversized func(T)
+}
+
+func newLimits[T any](count int, o batcherOptions) limits[T] {
+	return limits[T]{
+		count:       count,
+		weight:      options.Typed[func(T) int](o.weight, "batcher: WithMaxWeight"),
+		maxWeight:   o.maxWeight,
+		oversized:   o.oversized,
+		onOversized: options.Typed[func(T)](o.onOversized, "batcher: WithOversizedHandler"),
+	}
+}
+
+type batchBuffer[T any] struct {
+	items  []T
+	weight int
+}
+
+func (l *limits[T]) weightOf(item T) int {
+	if l.weight == nil || l.maxWeight <= 0 {
+		return 0
+	}
+
+	return l.weight(item)
+}
+
+// add добавляет item в buf и возвращает true, если buf пора отправить.
+// flush отправляет накопленное, emit - отдельный batch в обход buf.
+func (l *limits[T]) add(buf *batchBuffer[T], item T, flush func(), emit func([]T)) bool {
+	w := l.weightOf(item)
+
+	if l.maxWeight > 0 && w > l.maxWeight {
+		switch l.oversized {
+		case OversizedDrop:
+			if l.onOversized != nil {
+				l.onOversized(item)
+			}
+		default:
+			flush()
+			emit([]T{item})
+		}
+
+		return false
+	}
+
+	if l.maxWeight > 0 && len(buf.items) > 0 && buf.weight+w > l.maxWeight {
+		flush()
+	}
+
+	buf.items = append(buf.items, item)
+	buf.weight += w
+
+	return l.isFull(buf)
+}
+
+func (l *limits[T]) isFull(buf *batchBuffer[T]) bool {
+	return (l.count > 0 && len(buf.items) >= l.count) ||
+		(l.maxWeight > 0 && buf.weight >= l.maxWeight)
+}
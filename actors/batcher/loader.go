@@ -0,0 +1,131 @@
+package batcher
+
+import (
+	"context"
+
+	std "github.com/SlamJam/go-libs"
+	"github.com/SlamJam/go-libs/actors"
+	"github.com/SlamJam/go-libs/co"
+	"github.com/SlamJam/go-libs/xgo"
+	"github.com/pkg/errors"
+)
+
+var ErrNotFound = errors.New("key not found")
+
+type loadRequest[K comparable, V any] struct {
+	key     K
+	resolve func(V, error)
+}
+
+// Loader объединяет одиночные запросы по ключу в пакетные: ключи копятся в batch,
+// на каждый batch вызывается fetch, и каждый вызывающий получает свой результат.
+type Loader[K comparable, V any] struct {
+	actors.Actor
+
+	batcher *Batcher[loadRequest[K, V]]
+	fetch   func(context.Context, []K) (map[K]V, error)
+}
+
+// NewLoader создаёт Loader. fetch получает уникальные ключи batch в порядке первого запроса;
+// ключ, которого нет в результате, отклоняется с ErrNotFound. Ошибка или паника fetch отклоняет весь batch.
+// size и opts - как у NewBatcher.
+func NewLoader[K comparable, V any](fetch func(context.Context, []K) (map[K]V, error), size int, opts ...Opt) *Loader[K, V] {
+	l := &Loader[K, V]{
+		batcher: NewBatcher[loadRequest[K, V]](size, opts...),
+		fetch:   fetch,
+	}
+
+	l.batcher.onDrop = func(batch []loadRequest[K, V]) {
+		reject(batch, ErrClosed)
+	}
+
+	l.Actor = actors.NewActor(l.do)
+
+	return l
+}
+
+func reject[K comparable, V any](batch []loadRequest[K, V], err error) {
+	for _, r := range batch {
+		r.resolve(std.Zero[V](), err)
+	}
+}
+
+func (l *Loader[K, V]) do(ctx context.Context) error {
+	l.batcher.Start(ctx)
+
+	for batch := range l.batcher.C() {
+		l.load(ctx, batch)
+	}
+
+	return l.batcher.WaitUntilHalted(context.Background())
+}
+
+func (l *Loader[K, V]) load(ctx context.Context, batch []loadRequest[K, V]) {
+	// После отмены ctx доотправленные batch не загружаем
+	if err := ctx.Err(); err != nil {
+		reject(batch, err)
+		return
+	}
+
+	waiters := make(map[K][]func(V, error), len(batch))
+	keys := make([]K, 0, len(batch))
+
+	for _, r := range batch {
+		if _, ok := waiters[r.key]; !ok {
+			keys = append(keys, r.key)
+		}
+
+		waiters[r.key] = append(waiters[r.key], r.resolve)
+	}
+
+	var values map[K]V
+	var err error
+
+	if p := xgo.CatchPanic(func() { values, err = l.fetch(ctx, keys) }); p != nil {
+		err = *p
+	}
+
+	for _, key := range keys {
+		v, ok := values[key]
+
+		keyErr := err
+		if keyErr == nil && !ok {
+			keyErr = errors.Wrapf(ErrNotFound, "%v", key)
+		}
+
+		for _, resolve := range waiters[key] {
+			resolve(v, keyErr)
+		}
+	}
+}
+
+// Load ставит ключ в очередь и возвращает Promise со значением.
+// Если ключ не удалось поставить, Promise сразу отклонён с причиной.
+func (l *Loader[K, V]) Load(ctx context.Context, key K) co.Promise[V] {
+	p, resolve := co.NewPending[V]()
+
+	if err := l.batcher.Add(ctx, loadRequest[K, V]{key: key, resolve: resolve}); err != nil {
+		return co.NewRejected[V](err)
+	}
+
+	return p
+}
+
+// Shutdown прекращает приём ключей и загружает уже поставленные.
+// Ключи, которые не успели загрузить до истечения ctx, отклоняются с ErrClosed.
+func (l *Loader[K, V]) Shutdown(ctx context.Context) (dropped int, err error) {
+	// Batcher запускается из do, без ожидания Shutdown посчитал бы его не запущенным и отклонил ключи
+	if l.IsStarted() {
+		_ = l.batcher.WaitUntilStarted(ctx)
+	}
+
+	dropped, err = l.batcher.Shutdown(ctx)
+
+	if l.IsStarted() {
+		if haltErr := l.WaitUntilHalted(ctx); err == nil {
+			err = haltErr
+		}
+	}
+
+	return dropped, err
+}
@@ -0,0 +1,165 @@
+package batcher_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SlamJam/go-libs/actors/batcher"
+	"github.com/SlamJam/go-libs/co"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoader(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mu sync.Mutex
+	var calls [][]int
+
+	l := batcher.NewLoader(func(ctx context.Context, keys []int) (map[int]string, error) {
+		mu.Lock()
+		calls = append(calls, keys)
+		mu.Unlock()
+
+		result := map[int]string{}
+		for _, k := range keys {
+			if k != 404 {
+				result[k] = strconv.Itoa(k)
+			}
+		}
+
+		return result, nil
+	}, 10, batcher.WithInputBuffered(10), batcher.WithFlushInterval(20*time.Millisecond))
+
+	l.Start(ctx)
+
+	promises := co.MultiPromise[string]{}
+	for _, k := range []int{1, 2, 1, 404} {
+		promises = append(promises, l.Load(ctx, k))
+	}
+
+	for i, want := range []string{"1", "2", "1"} {
+		v, err := promises[i].Poll(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, want, v)
+	}
+
+	_, err := promises[3].Poll(ctx)
+	assert.ErrorIs(t, err, batcher.ErrNotFound)
+
+	mu.Lock()
+	assert.Equal(t, [][]int{{1, 2, 404}}, calls)
+	mu.Unlock()
+}
+
+func TestLoaderError(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	fail := errors.New("db is down")
+
+	l := batcher.NewLoader(func(ctx context.Context, keys []int) (map[int]int, error) {
+		if keys[0] == 0 {
+			panic("boom")
+		}
+
+		return nil, fail
+	}, 1)
+
+	l.Start(ctx)
+
+	_, err := l.Load(ctx, 1).Poll(ctx)
+	assert.ErrorIs(t, err, fail)
+
+	_, err = l.Load(ctx, 0).Poll(ctx)
+	assert.Error(t, err)
+}
+
+func TestLoaderShutdown(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	l := batcher.NewLoader(func(ctx context.Context, keys []int) (map[int]int, error) {
+		result := map[int]int{}
+		for _, k := range keys {
+			result[k] = k * 10
+		}
+
+		return result, nil
+	}, 100, batcher.WithInputBuffered(10))
+
+	l.Start(ctx)
+	assert.NoError(t, l.WaitUntilStarted(ctx))
+
+	p := l.Load(ctx, 7)
+
+	dropped, err := l.Shutdown(ctx)
+	assert.NoError(t, err)
+	assert.Zero(t, dropped)
+
+	v, err := p.Poll(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 70, v)
+
+	_, err = l.Load(ctx, 8).Poll(ctx)
+	assert.ErrorIs(t, err, batcher.ErrClosed)
+}
+
+func TestLoaderInterruptSettlesAll(t *testing.T) {
+	t.Parallel()
+
+	for range 20 {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		l := batcher.NewLoader(func(ctx context.Context, keys []int) (map[int]int, error) {
+			result := map[int]int{}
+			for _, k := range keys {
+				result[k] = k
+			}
+
+			return result, nil
+		}, 4, batcher.WithInputBuffered(16), batcher.WithFlushInterval(time.Millisecond))
+
+		l.Start(ctx)
+
+		var mu sync.Mutex
+		var promises []co.Promise[int]
+
+		var wg sync.WaitGroup
+		for i := range 8 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				for j := range 20 {
+					p := l.Load(context.Background(), i*100+j)
+
+					mu.Lock()
+					promises = append(promises, p)
+					mu.Unlock()
+				}
+			}()
+		}
+
+		time.Sleep(time.Millisecond)
+		cancel()
+		wg.Wait()
+
+		// Каждый Promise разрешается: значением, ErrClosed или ошибкой ctx
+		waitCtx, waitCancel := context.WithTimeout(context.Background(), time.Second)
+		for _, p := range promises {
+			_, err := p.Poll(waitCtx)
+			assert.NotErrorIs(t, err, context.DeadlineExceeded)
+		}
+		waitCancel()
+	}
+}
@@ -0,0 +1,128 @@
+package batcher
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// Batch - batch, выданный в аренду из пула (см. WithPooledBatches).
+// После обработки его нужно вернуть через Release; после этого ни Batch, ни слайс из Items использовать нельзя.
+// Batch - это ссылка на одну аренду: после Release она не оживает, даже если память batch уже выдана снова.
+type Batch[T any] struct {
+	lease *pooledBatch[T]
+	gen   uint64
+}
+
+// pooledBatch - переиспользуемая память batch. gen растёт на каждом Release, поэтому Batch
+// прошлых аренд отличаются от текущей.
+type pooledBatch[T any] struct {
+	items []T
+	pool  *sync.Pool
+	gen   atomic.Uint64
+}
+
+// WithPooledBatches отдаёт batch через Batches() объектами из пула вместо C().
+// Потребитель возвращает их через Release, и batcher работает без выделения памяти на каждый batch.
+func WithPooledBatches(pooled bool) Opt {
+	return func(o *batcherOptions) {
+		o.pooled = pooled
+	}
+}
+
+func (b Batch[T]) mustLeased() {
+	if b.lease.gen.Load() != b.gen {
+		panic("batcher: batch used after release")
+	}
+}
+
+// Items возвращает item batch. Слайс принадлежит Batch и переиспользуется после Release.
+func (b Batch[T]) Items() []T {
+	b.mustLeased()
+	return b.lease.items
+}
+
+func (b Batch[T]) Len() int {
+	b.mustLeased()
+	return len(b.lease.items)
+}
+
+// Release возвращает batch в пул. Повторный Release паникует.
+// Item затираются, поэтому чтение сохранённого слайса после Release детектор гонок поймает
+// как гонку с заполнением следующего batch.
+func (b Batch[T]) Release() {
+	if !b.lease.gen.CompareAndSwap(b.gen, b.gen+1) {
+		panic("batcher: batch released twice")
+	}
+
+	b.lease.recycle()
+}
+
+func (p *pooledBatch[T]) recycle() {
+	clear(p.items)
+	p.items = p.items[:0]
+	p.pool.Put(p)
+}
+
+func newBatchPool[T any]() *sync.Pool {
+	p := &sync.Pool{}
+	p.New = func() any {
+		return &pooledBatch[T]{pool: p}
+	}
+
+	return p
+}
+
+func (b *Batcher[T]) lease(items []T) Batch[T] {
+	p := b.pool.Get().(*pooledBatch[T])
+	p.items = append(p.items, items...)
+
+	return Batch[T]{lease: p, gen: p.gen.Load()}
+}
+
+// reusesBuffer сообщает, что слайс отправленного batch дальше переиспользуется буфером.
+// Так можно только с пулом: send копирует batch в арендованный, и потребитель буфер не видит.
+func (b *Batcher[T]) reusesBuffer() bool {
+	return b.pool != nil
+}
+
+// send отправляет batch потребителю, пока не закрыт stop1 или stop2. Возвращает false, если не удалось.
+func (b *Batcher[T]) send(items []T, stop1, stop2 <-chan struct{}) bool {
+	if b.pool == nil {
+		select {
+		case b.outCh <- items:
+			return true
+		case <-stop1:
+		case <-stop2:
+		}
+
+		return false
+	}
+
+	batch := b.lease(items)
+
+	select {
+	case b.leaseCh <- batch:
+		return true
+	case <-stop1:
+	case <-stop2:
+	}
+
+	batch.Release()
+
+	return false
+}
+
+// keep возвращает batch, который можно хранить после того, как буфер будет переиспользован.
+func (b *Batcher[T]) keep(items []T) []T {
+	if b.reusesBuffer() {
+		return slices.Clone(items)
+	}
+
+	return items
+}
+
+// Batches возвращает поток batch из пула. Требует WithPooledBatches, иначе nil.
+func (b *Batcher[T]) Batches() <-chan Batch[T] {
+	return b.leaseCh
+}
@@ -0,0 +1,54 @@
+package batcher_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/SlamJam/go-libs/actors/batcher"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatcherPooled(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	b := batcher.NewBatcher[int](2,
+		batcher.WithPooledBatches(true),
+		batcher.WithInputBuffered(5),
+		batcher.WithOutputBuffered(3),
+	)
+	b.Start(ctx)
+	assert.NoError(t, b.WaitUntilStarted(ctx))
+
+	for i := range 5 {
+		assert.NoError(t, b.Add(ctx, i))
+	}
+
+	// После Shutdown batcher больше не берёт batch из пула, и проверки после Release детерминированы
+	dropped, err := b.Shutdown(ctx)
+	assert.NoError(t, err)
+	assert.Zero(t, dropped)
+
+	var got [][]int
+	var stale []batcher.Batch[int]
+	for batch := range b.Batches() {
+		got = append(got, append([]int(nil), batch.Items()...))
+		batch.Release()
+
+		assert.PanicsWithValue(t, "batcher: batch used after release", func() { batch.Items() })
+		assert.PanicsWithValue(t, "batcher: batch released twice", batch.Release)
+
+		stale = append(stale, batch)
+	}
+
+	// Память batch могла быть выдана снова, но старые ссылки на неё не оживают
+	for _, batch := range stale {
+		assert.Panics(t, func() { batch.Len() })
+	}
+
+	assert.Equal(t, [][]int{{0, 1}, {2, 3}, {4}}, got)
+
+	_, ok := <-b.C()
+	assert.False(t, ok)
+}
@@ -0,0 +1,21 @@
+package actors
+
+import "time"
+
+// Clock абстрагирует время, чтобы в тестах можно было подменить его без реальных ожиданий.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+var RealClock Clock = realClock{}
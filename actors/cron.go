@@ -0,0 +1,284 @@
+package actors
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var ErrInvalidCronSpec = errors.New("invalid cron spec")
+
+// CronSchedule - разобранное cron-выражение.
+type CronSchedule struct {
+	second, minute, hour, dom, month, dow uint64
+
+	domStar, dowStar bool
+
+	// Если nil, используется зона времени, переданного в Next.
+	loc *time.Location
+}
+
+type cronBounds struct {
+	min, max uint
+	names    map[string]uint
+}
+
+var (
+	cronSeconds = cronBounds{min: 0, max: 59}
+	cronMinutes = cronBounds{min: 0, max: 59}
+	cronHours   = cronBounds{min: 0, max: 23}
+	cronDom     = cronBounds{min: 1, max: 31}
+	cronMonths  = cronBounds{min: 1, max: 12, names: map[string]uint{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// 0 и 7 - воскресенье
+	cronDow = cronBounds{min: 0, max: 7, names: map[string]uint{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseCron разбирает cron-выражение из 5 полей (минуты, часы, день месяца, месяц, день недели)
+// или из 6 полей, где первым идут секунды.
+// Поддерживаются *, ?, списки, диапазоны, шаги, имена месяцев и дней недели, дескрипторы вида @daily,
+// а также префикс CRON_TZ=<зона> или TZ=<зона>.
+func ParseCron(spec string) (*CronSchedule, error) {
+	s := &CronSchedule{}
+
+	spec = strings.TrimSpace(spec)
+	if strings.HasPrefix(spec, "CRON_TZ=") || strings.HasPrefix(spec, "TZ=") {
+		tz, rest, _ := strings.Cut(spec, " ")
+		_, name, _ := strings.Cut(tz, "=")
+
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			return nil, errors.Wrapf(ErrInvalidCronSpec, "location %q: %v", name, err)
+		}
+
+		s.loc = loc
+		spec = strings.TrimSpace(rest)
+	}
+
+	if expr, ok := cronDescriptors[strings.ToLower(spec)]; ok {
+		spec = expr
+	}
+
+	fields := strings.Fields(spec)
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return nil, errors.Wrapf(ErrInvalidCronSpec, "expected 5 or 6 fields, got %d in %q", len(fields), spec)
+	}
+
+	var err error
+
+	if s.second, _, err = parseCronField(fields[0], cronSeconds); err != nil {
+		return nil, err
+	}
+	if s.minute, _, err = parseCronField(fields[1], cronMinutes); err != nil {
+		return nil, err
+	}
+	if s.hour, _, err = parseCronField(fields[2], cronHours); err != nil {
+		return nil, err
+	}
+	if s.dom, s.domStar, err = parseCronField(fields[3], cronDom); err != nil {
+		return nil, err
+	}
+	if s.month, _, err = parseCronField(fields[4], cronMonths); err != nil {
+		return nil, err
+	}
+	if s.dow, s.dowStar, err = parseCronField(fields[5], cronDow); err != nil {
+		return nil, err
+	}
+
+	if s.dow&(1<<7) != 0 {
+		s.dow = s.dow&^(1<<7) | 1
+	}
+
+	return s, nil
+}
+
+// MustParseCron как ParseCron, но паникует при ошибке.
+func MustParseCron(spec string) *CronSchedule {
+	s, err := ParseCron(spec)
+	if err != nil {
+		panic(err)
+	}
+
+	return s
+}
+
+func parseCronField(field string, b cronBounds) (bits uint64, star bool, err error) {
+	for _, part := range strings.Split(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		lowStr, highStr, hasHigh := strings.Cut(rng, "-")
+
+		var low, high uint
+		step := uint(1)
+
+		if lowStr == "*" || lowStr == "?" {
+			if hasHigh {
+				return 0, false, errors.Wrapf(ErrInvalidCronSpec, "range with wildcard in %q", part)
+			}
+
+			low, high = b.min, b.max
+			star = true
+		} else {
+			if low, err = parseCronValue(lowStr, b); err != nil {
+				return 0, false, err
+			}
+
+			switch {
+			case hasHigh:
+				if high, err = parseCronValue(highStr, b); err != nil {
+					return 0, false, err
+				}
+			case hasStep:
+				high = b.max
+			default:
+				high = low
+			}
+		}
+
+		if hasStep {
+			n, convErr := strconv.ParseUint(stepStr, 10, 8)
+			if convErr != nil || n == 0 {
+				return 0, false, errors.Wrapf(ErrInvalidCronSpec, "bad step in %q", part)
+			}
+
+			step = uint(n)
+		}
+
+		if low < b.min || high > b.max || low > high {
+			return 0, false, errors.Wrapf(ErrInvalidCronSpec, "%q is out of range [%d, %d]", part, b.min, b.max)
+		}
+
+		for i := low; i <= high; i += step {
+			bits |= 1 << i
+		}
+	}
+
+	return bits, star, nil
+}
+
+func parseCronValue(s string, b cronBounds) (uint, error) {
+	if v, ok := b.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+
+	n, err := strconv.ParseUint(s, 10, 8)
+	if err != nil {
+		return 0, errors.Wrapf(ErrInvalidCronSpec, "bad value %q", s)
+	}
+
+	return uint(n), nil
+}
+
+func (s *CronSchedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+
+	if s.domStar || s.dowStar {
+		return dom && dow
+	}
+
+	return dom || dow
+}
+
+// Next возвращает ближайший момент строго после t, подходящий под расписание.
+// Если такого момента нет в ближайшие 5 лет, возвращается нулевое время.
+func (s *CronSchedule) Next(t time.Time) time.Time {
+	origLoc := t.Location()
+	loc := s.loc
+	if loc == nil {
+		loc = origLoc
+	}
+
+	t = t.In(loc)
+	t = t.Add(time.Second - time.Duration(t.Nanosecond()))
+
+	yearLimit := t.Year() + 5
+	// Как только одно из полей пришлось сдвинуть, младшие поля сбрасываются в минимум.
+	added := false
+
+wrap:
+	for t.Year() <= yearLimit {
+		for s.month&(1<<uint(t.Month())) == 0 {
+			if !added {
+				added = true
+				t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+			}
+
+			t = t.AddDate(0, 1, 0)
+			if t.Month() == time.January {
+				continue wrap
+			}
+		}
+
+		for !s.dayMatches(t) {
+			if !added {
+				added = true
+				t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+			}
+
+			t = t.AddDate(0, 0, 1)
+			if t.Day() == 1 {
+				continue wrap
+			}
+		}
+
+		for s.hour&(1<<uint(t.Hour())) == 0 {
+			if !added {
+				added = true
+				t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, loc)
+			}
+
+			t = t.Add(time.Hour)
+			if t.Hour() == 0 {
+				continue wrap
+			}
+		}
+
+		for s.minute&(1<<uint(t.Minute())) == 0 {
+			if !added {
+				added = true
+				t = t.Truncate(time.Minute)
+			}
+
+			t = t.Add(time.Minute)
+			if t.Minute() == 0 {
+				continue wrap
+			}
+		}
+
+		for s.second&(1<<uint(t.Second())) == 0 {
+			if !added {
+				added = true
+				t = t.Truncate(time.Second)
+			}
+
+			t = t.Add(time.Second)
+			if t.Second() == 0 {
+				continue wrap
+			}
+		}
+
+		return t.In(origLoc)
+	}
+
+	return time.Time{}
+}
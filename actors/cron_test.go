@@ -0,0 +1,56 @@
+package actors_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/SlamJam/go-libs/actors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCronNext(t *testing.T) {
+	t.Parallel()
+
+	base := time.Date(2024, time.January, 31, 23, 59, 30, 0, time.UTC)
+
+	cases := []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{"*/10 * * * * *", time.Date(2024, time.January, 31, 23, 59, 40, 0, time.UTC)},
+		{"30 9 * * mon-fri", time.Date(2024, time.February, 1, 9, 30, 0, 0, time.UTC)},
+		{"0 0 29 feb *", time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 12 1,15 * sun", time.Date(2024, time.February, 1, 12, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, time.February, 4, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{"CRON_TZ=Europe/Moscow 0 3 * * *", time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, c := range cases {
+		s, err := actors.ParseCron(c.spec)
+		if assert.NoError(t, err, c.spec) {
+			assert.Equal(t, c.want, s.Next(base), c.spec)
+		}
+	}
+
+	assert.True(t, actors.MustParseCron("0 0 30 feb *").Next(base).IsZero())
+}
+
+func TestCronParseErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* * 0 * *",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"* * * foo *",
+		"TZ=Nowhere/Never * * * * *",
+	} {
+		_, err := actors.ParseCron(spec)
+		assert.ErrorIs(t, err, actors.ErrInvalidCronSpec, spec)
+	}
+}
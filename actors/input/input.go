@@ -5,36 +5,65 @@ import (
 	"errors"
 	"sync"
 	"time"
+
+	"github.com/SlamJam/go-libs/options"
+	"github.com/SlamJam/go-libs/xsync"
 )
 
 var (
 	ErrClosed  = errors.New("input closed")
 	ErrTimeout = errors.New("timeout reached")
+	ErrFull    = errors.New("input is full")
 )
 
+// input - очередь с несколькими продюсерами и одним потребителем.
 type input[T any] struct {
-	ch        chan T
-	done      chan struct{}
-	closeOnce *sync.Once
+	ch   chan T
+	done chan struct{}
+	// Add держат на чтение, пока отправляют; Close берёт на запись, чтобы дождаться их перед закрытием ch
+	addMu     sync.RWMutex
+	closeOnce sync.Once
+}
+
+type inputOptions struct {
+	capacity int
+}
+
+type Opt = options.Opt[inputOptions]
+
+// WithCapacity задаёт, сколько item input хранит, пока потребитель их не прочитал.
+func WithCapacity(n int) Opt {
+	return func(o *inputOptions) {
+		o.capacity = n
+	}
 }
 
+// Pub - сторона продюсеров. Канал наружу не отдаётся, чтобы отправка не могла обойти Close:
+// вместо отправки в select используйте AddWithContext.
 type Pub[T any] interface {
-	Ch() chan<- T
 	Add(item T) error
 	AddWithContext(ctx context.Context, item T) error
 	AddWithTimeout(to time.Duration, item T) error
+	// TryAdd добавляет item, только если это возможно без ожидания, иначе возвращает ErrFull.
+	TryAdd(item T) error
+	// AddBatch добавляет item по порядку и возвращает, сколько успел добавить.
+	// Item других продюсеров могут оказаться между ними.
+	AddBatch(ctx context.Context, items []T) (int, error)
 }
 
 type Priv[T any] interface {
+	// ChIn закрывается после Close, когда потребитель прочитал все item.
 	ChIn() <-chan T
+	// Close прекращает приём item, дожидается Add, которые уже отправляют, и закрывает ChIn.
 	Close()
 }
 
-func NewInput[T any]() (Pub[T], Priv[T]) {
-	in := input[T]{
-		ch:        make(chan T),
-		done:      make(chan struct{}),
-		closeOnce: &sync.Once{},
+func NewInput[T any](opts ...Opt) (Pub[T], Priv[T]) {
+	o := options.Create(opts...)
+
+	in := &input[T]{
+		ch:   make(chan T, o.capacity),
+		done: make(chan struct{}),
 	}
 
 	return in, in
@@ -42,55 +71,82 @@ func NewInput[T any]() (Pub[T], Priv[T]) {
 
 /* InputPub */
 
-func (in input[T]) Ch() chan<- T {
-	return in.ch
-}
+// add отправляет item, пока не закрыт input или не сработал stop.
+func (in *input[T]) add(item T, stop <-chan struct{}, stopErr func() error) error {
+	in.addMu.RLock()
+	defer in.addMu.RUnlock()
 
-func (in input[T]) Add(item T) error {
 	select {
-	case in.ch <- item:
 	case <-in.done:
 		return ErrClosed
+	default:
 	}
 
-	return nil
-}
-
-func (in input[T]) AddWithContext(ctx context.Context, item T) error {
 	select {
 	case in.ch <- item:
+		return nil
 	case <-in.done:
 		return ErrClosed
-	case <-ctx.Done():
-		return ctx.Err()
+	case <-stop:
+		return stopErr()
 	}
+}
 
-	return nil
+func (in *input[T]) Add(item T) error {
+	return in.add(item, nil, nil)
 }
 
-func (in input[T]) AddWithTimeout(to time.Duration, item T) error {
-	timer := time.NewTimer(to)
-	defer timer.Stop()
+func (in *input[T]) AddWithContext(ctx context.Context, item T) error {
+	return in.add(item, ctx.Done(), ctx.Err)
+}
+
+func (in *input[T]) AddWithTimeout(to time.Duration, item T) error {
+	ctx, cancel := context.WithTimeout(context.Background(), to)
+	defer cancel()
+
+	return in.add(item, ctx.Done(), func() error { return ErrTimeout })
+}
+
+func (in *input[T]) TryAdd(item T) error {
+	in.addMu.RLock()
+	defer in.addMu.RUnlock()
 
 	select {
-	case in.ch <- item:
 	case <-in.done:
 		return ErrClosed
-	case <-timer.C:
-		return ErrTimeout
+	default:
+	}
+
+	select {
+	case in.ch <- item:
+		return nil
+	default:
+		return ErrFull
 	}
+}
 
-	return nil
+func (in *input[T]) AddBatch(ctx context.Context, items []T) (int, error) {
+	for i, item := range items {
+		if err := in.AddWithContext(ctx, item); err != nil {
+			return i, err
+		}
+	}
+
+	return len(items), nil
 }
 
 /* InputPriv */
 
-func (in input[T]) ChIn() <-chan T {
+func (in *input[T]) ChIn() <-chan T {
 	return in.ch
 }
 
-func (in input[T]) Close() {
+func (in *input[T]) Close() {
 	in.closeOnce.Do(func() {
 		close(in.done)
+
+		// Дожидаемся Add, которые успели пройти проверку до закрытия
+		xsync.Barrier(&in.addMu)
+		close(in.ch)
 	})
 }
@@ -0,0 +1,67 @@
+package input_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SlamJam/go-libs/actors/input"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInputCapacity(t *testing.T) {
+	t.Parallel()
+
+	pub, priv := input.NewInput[int](input.WithCapacity(2))
+
+	assert.NoError(t, pub.TryAdd(1))
+	assert.NoError(t, pub.TryAdd(2))
+	assert.ErrorIs(t, pub.TryAdd(3), input.ErrFull)
+	assert.ErrorIs(t, pub.AddWithTimeout(10*time.Millisecond, 3), input.ErrTimeout)
+
+	priv.Close()
+
+	assert.ErrorIs(t, pub.Add(3), input.ErrClosed)
+
+	// Принятые до закрытия item дочитываются, затем ChIn закрыт
+	var got []int
+	for item := range priv.ChIn() {
+		got = append(got, item)
+	}
+
+	assert.Equal(t, []int{1, 2}, got)
+}
+
+func TestInputClose(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pub, priv := input.NewInput[int]()
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			_, err := pub.AddBatch(ctx, []int{1, 2, 3})
+			if err != nil {
+				assert.ErrorIs(t, err, input.ErrClosed)
+			}
+		}()
+	}
+
+	var n int
+	for range priv.ChIn() {
+		n++
+		if n == 5 {
+			priv.Close()
+		}
+	}
+
+	wg.Wait()
+	assert.GreaterOrEqual(t, n, 5)
+}
@@ -0,0 +1,141 @@
+package actors
+
+import (
+	"context"
+	"time"
+
+	"github.com/SlamJam/go-libs/xgo"
+	"github.com/SlamJam/go-libs/xsync"
+	"github.com/pkg/errors"
+)
+
+// PanicPolicy определяет, что делает актор после паники в main.
+type PanicPolicy int
+
+const (
+	// PanicPolicyHalt останавливает актор, паника возвращается из Error() как xgo.PanicError.
+	PanicPolicyHalt PanicPolicy = iota
+	// PanicPolicyRestart запускает main заново, пока актор не прерван.
+	// Перед перезапуском выдерживается пауза, см. WithRestartBackoff.
+	PanicPolicyRestart
+	// PanicPolicyRepanic пробрасывает панику дальше как *xgo.PanicError со стеком исходной паники,
+	// что завершает процесс.
+	PanicPolicyRepanic
+)
+
+const (
+	defaultRestartBackoff    = 10 * time.Millisecond
+	defaultMaxRestartBackoff = 5 * time.Second
+)
+
+type restartBackoff struct {
+	initial time.Duration
+	max     time.Duration
+}
+
+// next возвращает паузу перед перезапуском: она удваивается после каждой паники подряд до max
+// и сбрасывается на initial, если main перед паникой проработал дольше max.
+func (b restartBackoff) next(prev, ran time.Duration) time.Duration {
+	if prev == 0 || ran > b.max {
+		return b.initial
+	}
+
+	return min(2*prev, b.max)
+}
+
+type PanicReport struct {
+	ActorName   string
+	Generation  uint64
+	Payload     any
+	Stack       []byte
+	GoroutineID uint64
+	Time        time.Time
+}
+
+type PanicHandler func(PanicReport)
+
+var globalPanicHandler xsync.Value[PanicHandler]
+
+// SetGlobalPanicHandler задаёт обработчик, вызываемый при панике в любом акторе
+// после обработчика самого актора.
+func SetGlobalPanicHandler(h PanicHandler) {
+	globalPanicHandler.Store(h)
+}
+
+func WithPanicHandler(h PanicHandler) Opt {
+	return func(o *actorOptions) {
+		o.onPanic = h
+	}
+}
+
+func WithPanicPolicy(policy PanicPolicy) Opt {
+	return func(o *actorOptions) {
+		o.panicPolicy = policy
+	}
+}
+
+// WithRestartBackoff задаёт паузу перед перезапуском по PanicPolicyRestart: первая пауза initial,
+// каждая следующая паника подряд удваивает её, но не больше max. По умолчанию от 10ms до 5s.
+func WithRestartBackoff(initial, max time.Duration) Opt {
+	if initial <= 0 || max < initial {
+		panic("actors: restart backoff must be positive and not greater than max")
+	}
+
+	return func(o *actorOptions) {
+		o.restartBackoff = restartBackoff{initial: initial, max: max}
+	}
+}
+
+// waitRestart выдерживает паузу перед перезапуском и сообщает, можно ли перезапускать main.
+func waitRestart(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func callPanicHandler(h PanicHandler, report PanicReport) {
+	if h == nil {
+		return
+	}
+
+	// Паника в обработчике не должна ронять актор
+	_ = xgo.CatchPanic(func() { h(report) })
+}
+
+// handlePanic вызывает обработчики, если err - паника, и сообщает, нужно ли перезапустить main.
+func (c *actor) handlePanic(ctx context.Context, err error) (restart bool) {
+	var panicErr xgo.PanicError
+	if !errors.As(err, &panicErr) {
+		return false
+	}
+
+	report := PanicReport{
+		ActorName:   c.opts.name,
+		Generation:  c.generation.Load(),
+		Payload:     panicErr.Payload,
+		Stack:       panicErr.Stack(),
+		GoroutineID: xgo.GoroutineID(),
+		Time:        time.Now(),
+	}
+
+	callPanicHandler(c.opts.onPanic, report)
+
+	if h, ok := globalPanicHandler.Load(); ok {
+		callPanicHandler(h, report)
+	}
+
+	switch c.opts.panicPolicy {
+	case PanicPolicyRestart:
+		return ctx.Err() == nil
+	case PanicPolicyRepanic:
+		panic(&panicErr)
+	default:
+		return false
+	}
+}
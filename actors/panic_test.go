@@ -0,0 +1,148 @@
+package actors_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/SlamJam/go-libs/actors"
+	"github.com/SlamJam/go-libs/xgo"
+	"github.com/stretchr/testify/assert"
+)
+
+// Глобальный обработчик общий для всех тестов, поэтому тест не параллельный
+func TestPanicReport(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reports := make(chan actors.PanicReport, 1)
+	var globalCalls atomic.Int32
+
+	actors.SetGlobalPanicHandler(func(r actors.PanicReport) {
+		if r.ActorName == "reported" {
+			globalCalls.Add(1)
+		}
+	})
+	t.Cleanup(func() { actors.SetGlobalPanicHandler(nil) })
+
+	c := actors.NewActor(
+		func(ctx context.Context) error {
+			panic("boom")
+		},
+		actors.WithName("reported"),
+		actors.WithPanicHandler(func(r actors.PanicReport) {
+			reports <- r
+		}),
+	)
+
+	c.Start(ctx)
+	err := c.WaitUntilHalted(ctx)
+	assert.NoError(t, err)
+
+	r := <-reports
+	assert.Equal(t, "reported", r.ActorName)
+	assert.Equal(t, uint64(1), r.Generation)
+	assert.Equal(t, "boom", r.Payload)
+	assert.NotEmpty(t, r.Stack)
+	assert.NotZero(t, r.GoroutineID)
+	assert.False(t, r.Time.IsZero())
+
+	assert.Equal(t, int32(1), globalCalls.Load())
+	assert.ErrorAs(t, c.Error(), &xgo.PanicError{})
+}
+
+func TestPanicPolicyRestart(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var runs atomic.Int32
+
+	c := actors.NewActor(
+		func(ctx context.Context) error {
+			if runs.Add(1) < 3 {
+				panic("boom")
+			}
+
+			return nil
+		},
+		actors.WithPanicPolicy(actors.PanicPolicyRestart),
+		actors.WithRestartBackoff(time.Millisecond, time.Millisecond),
+	)
+
+	c.Start(ctx)
+	err := c.WaitUntilHalted(ctx)
+	assert.NoError(t, err)
+
+	assert.NoError(t, c.Error())
+	assert.Equal(t, int32(3), runs.Load())
+	assert.Equal(t, uint64(3), c.(actors.Restartable).Generation())
+	assert.Equal(t, uint64(2), c.(actors.StatProvider).Stat().Panics)
+}
+
+func TestPanicPolicyRestartBackoff(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var runs atomic.Int32
+
+	c := actors.NewActor(
+		func(ctx context.Context) error {
+			if runs.Add(1) < 4 {
+				panic("boom")
+			}
+
+			return nil
+		},
+		actors.WithPanicPolicy(actors.PanicPolicyRestart),
+		actors.WithRestartBackoff(20*time.Millisecond, 30*time.Millisecond),
+	)
+
+	began := time.Now()
+	c.Start(ctx)
+	assert.NoError(t, c.WaitUntilHalted(ctx))
+
+	// Паузы 20ms, 30ms и 30ms: удвоение ограничено max
+	assert.GreaterOrEqual(t, time.Since(began), 80*time.Millisecond)
+	assert.Equal(t, int32(4), runs.Load())
+}
+
+func TestPanicPolicyRestartInterruptDuringBackoff(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	var runs atomic.Int32
+	panicked := make(chan struct{}, 1)
+
+	c := actors.NewActor(
+		func(ctx context.Context) error {
+			runs.Add(1)
+			panicked <- struct{}{}
+			panic("boom")
+		},
+		actors.WithPanicPolicy(actors.PanicPolicyRestart),
+		actors.WithRestartBackoff(time.Hour, time.Hour),
+	)
+
+	c.Start(ctx)
+	<-panicked
+
+	assert.NoError(t, c.Interrupt(ctx))
+	assert.NoError(t, c.WaitUntilHalted(ctx))
+
+	assert.Equal(t, int32(1), runs.Load())
+	assert.ErrorAs(t, c.Error(), &xgo.PanicError{})
+}
+
+func TestWithRestartBackoffValidates(t *testing.T) {
+	t.Parallel()
+
+	assert.Panics(t, func() { actors.WithRestartBackoff(0, time.Second) })
+	assert.Panics(t, func() { actors.WithRestartBackoff(time.Second, time.Millisecond) })
+}
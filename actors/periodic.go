@@ -0,0 +1,228 @@
+package actors
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/SlamJam/go-libs/options"
+	"github.com/SlamJam/go-libs/xchan"
+	"github.com/SlamJam/go-libs/xgo"
+	"github.com/pkg/errors"
+)
+
+// OverlapPolicy определяет, что делать с очередным тиком, если предыдущий запуск ещё не завершён.
+type OverlapPolicy int
+
+const (
+	// OverlapSkip пропускает тик.
+	OverlapSkip OverlapPolicy = iota
+	// OverlapQueue откладывает запуск до завершения текущего. В очереди может ждать не более одного запуска,
+	// остальные тики пропускаются.
+	OverlapQueue
+	// OverlapParallel запускает очередной прогон параллельно с текущим.
+	OverlapParallel
+)
+
+type PeriodicStat struct {
+	Runs      uint64
+	Succeeded uint64
+	Failed    uint64
+	TimedOut  uint64
+	Skipped   uint64
+
+	LastStartedAt time.Time
+	LastDuration  time.Duration
+	LastError     error
+}
+
+type periodicOptions struct {
+	initialDelay   time.Duration
+	jitter         time.Duration
+	runImmediately bool
+	overlap        OverlapPolicy
+	runTimeout     time.Duration
+	actorOpts      []Opt
+}
+
+type PeriodicOpt = options.Opt[periodicOptions]
+
+// WithInitialDelay задаёт задержку перед первым запуском. По умолчанию равна интервалу.
+func WithInitialDelay(d time.Duration) PeriodicOpt {
+	return func(o *periodicOptions) {
+		o.initialDelay = d
+	}
+}
+
+// WithJitter добавляет к каждому интервалу случайную задержку из [0, jitter).
+func WithJitter(jitter time.Duration) PeriodicOpt {
+	return func(o *periodicOptions) {
+		o.jitter = jitter
+	}
+}
+
+// WithRunImmediately запускает f сразу после старта актора, игнорируя начальную задержку.
+func WithRunImmediately(immediately bool) PeriodicOpt {
+	return func(o *periodicOptions) {
+		o.runImmediately = immediately
+	}
+}
+
+func WithOverlapPolicy(policy OverlapPolicy) PeriodicOpt {
+	return func(o *periodicOptions) {
+		o.overlap = policy
+	}
+}
+
+// WithRunTimeout ограничивает время одного запуска.
+func WithRunTimeout(timeout time.Duration) PeriodicOpt {
+	return func(o *periodicOptions) {
+		o.runTimeout = timeout
+	}
+}
+
+func WithActorOptions(opts ...Opt) PeriodicOpt {
+	return func(o *periodicOptions) {
+		o.actorOpts = append(o.actorOpts[:len(o.actorOpts):len(o.actorOpts)], opts...)
+	}
+}
+
+type periodic struct {
+	interval time.Duration
+	f        func(context.Context) error
+	opts     periodicOptions
+}
+
+// NewPeriodic создаёт актор, вызывающий f каждые interval.
+// Ошибки и паники f не останавливают актор, а попадают в статистику. interval должен быть больше 0.
+func NewPeriodic(interval time.Duration, f func(context.Context) error, opts ...PeriodicOpt) ActorWithStat[PeriodicStat] {
+	if interval <= 0 {
+		panic("actors: periodic interval must be positive")
+	}
+
+	p := &periodic{
+		interval: interval,
+		f:        f,
+		opts: periodicOptions{
+			initialDelay: interval,
+		},
+	}
+
+	options.ApplyInto(&p.opts, opts...)
+
+	return NewActorWithStat(p.do, p.opts.actorOpts...)
+}
+
+func (p *periodic) nextDelay() time.Duration {
+	d := p.interval
+	if p.opts.jitter > 0 {
+		d += rand.N(p.opts.jitter)
+	}
+
+	return d
+}
+
+func (p *periodic) runOnce(ctx context.Context, st StatUpdater[PeriodicStat]) {
+	if p.opts.runTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.opts.runTimeout)
+		defer cancel()
+	}
+
+	startedAt := time.Now()
+	st.Modify(func(s *PeriodicStat) {
+		s.Runs++
+		s.LastStartedAt = startedAt
+	})
+
+	err := xgo.CatchPanicInErr(func() error {
+		return p.f(ctx)
+	})
+	AddProcessed(ctx, 1)
+
+	st.Modify(func(s *PeriodicStat) {
+		s.LastDuration = time.Since(startedAt)
+		s.LastError = err
+
+		switch {
+		case err == nil:
+			s.Succeeded++
+		case errors.Is(err, context.DeadlineExceeded) && ctx.Err() != nil:
+			s.TimedOut++
+			s.Failed++
+		default:
+			s.Failed++
+		}
+	})
+}
+
+func (p *periodic) do(ctx context.Context, st StatUpdater[PeriodicStat]) error {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	var busy atomic.Bool
+	trigger := make(chan struct{}, 1)
+
+	if p.opts.overlap != OverlapParallel {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for {
+				select {
+				case <-trigger:
+					p.runOnce(ctx, st)
+					busy.Store(false)
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	tick := func() {
+		switch p.opts.overlap {
+		case OverlapParallel:
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				p.runOnce(ctx, st)
+			}()
+
+			return
+		case OverlapSkip:
+			if busy.CompareAndSwap(false, true) {
+				trigger <- struct{}{}
+				return
+			}
+		default:
+			if xchan.TrySendNonBlocking(trigger, struct{}{}) {
+				return
+			}
+		}
+
+		st.Modify(func(s *PeriodicStat) {
+			s.Skipped++
+		})
+	}
+
+	delay := p.opts.initialDelay
+	if p.opts.runImmediately {
+		delay = 0
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			tick()
+			timer.Reset(p.nextDelay())
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
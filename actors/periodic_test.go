@@ -0,0 +1,107 @@
+package actors_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/SlamJam/go-libs/actors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeriodic(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls atomic.Int32
+
+	p := actors.NewPeriodic(5*time.Millisecond, func(ctx context.Context) error {
+		calls.Add(1)
+		return nil
+	}, actors.WithRunImmediately(true))
+
+	p.Start(ctx)
+
+	assert.Eventually(t, func() bool {
+		return calls.Load() >= 3
+	}, time.Second, time.Millisecond)
+
+	err := p.Interrupt(ctx)
+	assert.NoError(t, err)
+
+	err = p.WaitUntilHalted(ctx)
+	assert.NoError(t, err)
+
+	stat := p.GetStat()
+	assert.Equal(t, uint64(calls.Load()), stat.Runs)
+	assert.Equal(t, stat.Runs, stat.Succeeded)
+}
+
+func TestPeriodicSkipOverlap(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	release := make(chan struct{})
+
+	p := actors.NewPeriodic(time.Millisecond, func(ctx context.Context) error {
+		<-release
+		return nil
+	},
+		actors.WithRunImmediately(true),
+		actors.WithOverlapPolicy(actors.OverlapSkip),
+	)
+
+	p.Start(ctx)
+
+	assert.Eventually(t, func() bool {
+		return p.GetStat().Skipped >= 3
+	}, time.Second, time.Millisecond)
+
+	err := p.Interrupt(ctx)
+	assert.NoError(t, err)
+
+	close(release)
+
+	err = p.WaitUntilHalted(ctx)
+	assert.NoError(t, err)
+
+	assert.Equal(t, uint64(1), p.GetStat().Runs)
+}
+
+func TestPeriodicRunTimeout(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	p := actors.NewPeriodic(time.Hour, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	},
+		actors.WithRunImmediately(true),
+		actors.WithRunTimeout(time.Millisecond),
+	)
+
+	p.Start(ctx)
+
+	assert.Eventually(t, func() bool {
+		return p.GetStat().TimedOut == 1
+	}, time.Second, time.Millisecond)
+
+	err := p.Interrupt(ctx)
+	assert.NoError(t, err)
+}
+
+func TestPeriodicInterval(t *testing.T) {
+	t.Parallel()
+
+	f := func(ctx context.Context) error { return nil }
+
+	assert.Panics(t, func() { actors.NewPeriodic(0, f) })
+	assert.Panics(t, func() { actors.NewPeriodic(-time.Second, f) })
+}
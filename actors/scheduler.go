@@ -0,0 +1,264 @@
+package actors
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/SlamJam/go-libs/options"
+	"github.com/SlamJam/go-libs/xchan"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrJobAlreadyExists = errors.New("job already exists")
+	ErrJobNotFound      = errors.New("job not found")
+)
+
+type schedulerOptions struct {
+	clock Clock
+	loc   *time.Location
+}
+
+type SchedulerOpt = options.Opt[schedulerOptions]
+
+// WithClock подменяет источник времени. По умолчанию RealClock.
+func WithClock(clock Clock) SchedulerOpt {
+	return func(o *schedulerOptions) {
+		o.clock = clock
+	}
+}
+
+// WithLocation задаёт зону времени для выражений без CRON_TZ. По умолчанию time.Local.
+func WithLocation(loc *time.Location) SchedulerOpt {
+	return func(o *schedulerOptions) {
+		o.loc = loc
+	}
+}
+
+// JobInfo - снимок состояния задачи планировщика.
+type JobInfo struct {
+	Name    string
+	Spec    string
+	Next    time.Time
+	Last    time.Time
+	Running bool
+	Runs    uint64
+	Skipped uint64
+	// Ошибка последнего запуска, nil пока запуск идёт
+	LastError error
+}
+
+type cronJob struct {
+	name     string
+	spec     string
+	schedule *CronSchedule
+	actor    *actor
+
+	next    time.Time
+	last    time.Time
+	skipped uint64
+}
+
+func (j *cronJob) info() JobInfo {
+	return JobInfo{
+		Name:      j.name,
+		Spec:      j.spec,
+		Next:      j.next,
+		Last:      j.last,
+		Running:   j.actor.IsStarted() && !j.actor.IsHalted(),
+		Runs:      j.actor.Generation(),
+		Skipped:   j.skipped,
+		LastError: j.actor.Error(),
+	}
+}
+
+// Scheduler запускает задачи по cron-расписанию.
+// Каждая задача - перезапускаемый дочерний актор, поэтому запуски одной задачи никогда не пересекаются:
+// если к моменту срабатывания предыдущий запуск ещё идёт, срабатывание пропускается.
+type Scheduler struct {
+	Actor
+
+	opts schedulerOptions
+
+	mu   sync.Mutex
+	jobs map[string]*cronJob
+	wake chan struct{}
+}
+
+func NewScheduler(opts ...SchedulerOpt) *Scheduler {
+	s := &Scheduler{
+		opts: schedulerOptions{
+			clock: RealClock,
+			loc:   time.Local,
+		},
+		jobs: map[string]*cronJob{},
+		wake: make(chan struct{}, 1),
+	}
+
+	options.ApplyInto(&s.opts, opts...)
+
+	s.Actor = NewActor(s.do)
+
+	return s
+}
+
+// AddJob регистрирует задачу. Можно вызывать как до, так и после старта планировщика.
+func (s *Scheduler) AddJob(name, spec string, f func(context.Context) error) error {
+	schedule, err := ParseCron(spec)
+	if err != nil {
+		return err
+	}
+
+	if schedule.loc == nil {
+		schedule.loc = s.opts.loc
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.jobs[name]; ok {
+		return errors.Wrap(ErrJobAlreadyExists, name)
+	}
+
+	s.jobs[name] = &cronJob{
+		name:     name,
+		spec:     spec,
+		schedule: schedule,
+		actor:    newActor(f, WithRestartable(true)),
+		next:     schedule.Next(s.opts.clock.Now()),
+	}
+
+	xchan.TrySendNonBlocking(s.wake, struct{}{})
+
+	return nil
+}
+
+// RemoveJob снимает задачу с расписания. Уже идущий запуск прерывается.
+func (s *Scheduler) RemoveJob(ctx context.Context, name string) error {
+	s.mu.Lock()
+	j, ok := s.jobs[name]
+	delete(s.jobs, name)
+	s.mu.Unlock()
+
+	if !ok {
+		return errors.Wrap(ErrJobNotFound, name)
+	}
+
+	xchan.TrySendNonBlocking(s.wake, struct{}{})
+
+	if j.actor.IsRunning() {
+		_ = j.actor.Interrupt(ctx)
+	}
+
+	return nil
+}
+
+func (s *Scheduler) Job(name string) (JobInfo, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	j, ok := s.jobs[name]
+	if !ok {
+		return JobInfo{}, false
+	}
+
+	return j.info(), true
+}
+
+// Jobs возвращает состояние всех задач, отсортированное по имени.
+func (s *Scheduler) Jobs() []JobInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]JobInfo, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		result = append(result, j.info())
+	}
+
+	sort.Slice(result, func(i, k int) bool {
+		return result[i].Name < result[k].Name
+	})
+
+	return result
+}
+
+func (s *Scheduler) earliest() (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var result time.Time
+	for _, j := range s.jobs {
+		if j.next.IsZero() {
+			continue
+		}
+
+		if result.IsZero() || j.next.Before(result) {
+			result = j.next
+		}
+	}
+
+	return result, !result.IsZero()
+}
+
+func (s *Scheduler) runDue(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.opts.clock.Now()
+	for _, j := range s.jobs {
+		if j.next.IsZero() || j.next.After(now) {
+			continue
+		}
+
+		if j.actor.Start(ctx) {
+			j.last = now
+		} else {
+			j.skipped++
+		}
+
+		j.next = j.schedule.Next(now)
+	}
+}
+
+func (s *Scheduler) waitJobs() {
+	s.mu.Lock()
+	jobs := make([]*cronJob, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		jobs = append(jobs, j)
+	}
+	s.mu.Unlock()
+
+	for _, j := range jobs {
+		if j.actor.IsStarted() {
+			_ = j.actor.WaitUntilHalted(context.Background())
+		}
+	}
+}
+
+func (s *Scheduler) do(ctx context.Context) error {
+	defer s.waitJobs()
+
+	s.mu.Lock()
+	now := s.opts.clock.Now()
+	for _, j := range s.jobs {
+		j.next = j.schedule.Next(now)
+	}
+	s.mu.Unlock()
+
+	for {
+		var timer <-chan time.Time
+		if next, ok := s.earliest(); ok {
+			timer = s.opts.clock.After(next.Sub(s.opts.clock.Now()))
+		}
+
+		select {
+		case <-timer:
+			s.runDue(ctx)
+		case <-s.wake:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
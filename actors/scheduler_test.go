@@ -0,0 +1,130 @@
+package actors_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/SlamJam/go-libs/actors"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeClockWaiter struct {
+	at time.Time
+	ch chan time.Time
+}
+
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeClockWaiter
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+	} else {
+		c.waiters = append(c.waiters, fakeClockWaiter{at: c.now.Add(d), ch: ch})
+	}
+
+	return ch
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+
+	pending := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.at.After(c.now) {
+			pending = append(pending, w)
+		} else {
+			w.ch <- c.now
+		}
+	}
+	c.waiters = pending
+}
+
+func (c *fakeClock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.waiters)
+}
+
+func TestScheduler(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	start := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+	clock := &fakeClock{now: start}
+
+	s := actors.NewScheduler(actors.WithClock(clock), actors.WithLocation(time.UTC))
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+
+	err := s.AddJob("job", "*/5 * * * * *", func(ctx context.Context) error {
+		calls.Add(1)
+		<-release
+		return nil
+	})
+	assert.NoError(t, err)
+
+	err = s.AddJob("job", "* * * * *", nil)
+	assert.ErrorIs(t, err, actors.ErrJobAlreadyExists)
+
+	s.Start(ctx)
+
+	info, ok := s.Job("job")
+	assert.True(t, ok)
+	assert.Equal(t, start.Add(5*time.Second), info.Next)
+
+	waitAndAdvance := func() {
+		assert.Eventually(t, func() bool { return clock.Waiters() > 0 }, time.Second, time.Millisecond)
+		clock.Advance(5 * time.Second)
+	}
+
+	waitAndAdvance()
+	assert.Eventually(t, func() bool { return calls.Load() == 1 }, time.Second, time.Millisecond)
+
+	// Предыдущий запуск ещё идёт, поэтому срабатывание пропускается
+	waitAndAdvance()
+	assert.Eventually(t, func() bool {
+		info, _ := s.Job("job")
+		return info.Skipped == 1
+	}, time.Second, time.Millisecond)
+
+	info, _ = s.Job("job")
+	assert.True(t, info.Running)
+	assert.Equal(t, uint64(1), info.Runs)
+	assert.Equal(t, start.Add(5*time.Second), info.Last)
+	assert.Equal(t, start.Add(15*time.Second), info.Next)
+
+	close(release)
+
+	err = s.Interrupt(ctx)
+	assert.NoError(t, err)
+
+	err = s.WaitUntilHalted(ctx)
+	assert.NoError(t, err)
+
+	assert.Equal(t, int32(1), calls.Load())
+}
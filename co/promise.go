@@ -144,6 +144,22 @@ func NewRejected[T any](err error) Promise[T] {
 	return p
 }
 
+// NewPending создаёт промис без функции, который завершается вызовом resolve.
+// Повторные вызовы resolve игнорируются.
+func NewPending[T any]() (p Promise[T], resolve func(T, error)) {
+	p = NewLazyPromise[T](nil)
+	p.launched.Store(true)
+
+	var once sync.Once
+	resolve = func(result T, err error) {
+		once.Do(func() {
+			p.onComplete(result, err)
+		})
+	}
+
+	return p, resolve
+}
+
 var Resolved = NewResolved(std.Void{})
 
 var ErrRejected = errors.New("rejected")
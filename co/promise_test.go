@@ -200,3 +200,36 @@ func TestUncompletedValuePanic(t *testing.T) {
 
 	p.Value() // This should panic
 }
+
+func TestPendingPromise(t *testing.T) {
+	p, resolve := NewPending[int]()
+
+	if p.IsCompleted() {
+		t.Error("Expected pending promise not to be completed")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := p.Poll(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded error, got %v", err)
+	}
+
+	go resolve(50, nil)
+
+	result, err := p.Poll(context.Background())
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if result != 50 {
+		t.Errorf("Expected result 50, got %v", result)
+	}
+
+	// Повторное разрешение игнорируется
+	resolve(60, errors.New("ignored"))
+
+	result, err = p.Value()
+	if err != nil || result != 50 {
+		t.Errorf("Expected result 50 without error, got %v, %v", result, err)
+	}
+}
@@ -7,11 +7,13 @@ toolchain go1.24.2
 require (
 	github.com/pkg/errors v0.9.1
 	github.com/stretchr/testify v1.8.2
+	go.uber.org/goleak v1.3.0
 	go.uber.org/multierr v1.11.0
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/kr/text v0.2.0 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
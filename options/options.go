@@ -1,5 +1,7 @@
 package options
 
+import "fmt"
+
 type Opt[T any] func(*T)
 
 func ApplyInto[T any](options *T, opts ...Opt[T]) {
@@ -19,3 +21,21 @@ func Create[T any](opts ...Opt[T]) T {
 	ApplyInto(&options, opts...)
 	return options
 }
+
+// Typed приводит значение generic-опции, сохранённое в опциях как any, к типу F.
+// nil даёт нулевое значение F. Несовпадение типа - ошибка программиста, поэтому Typed паникует
+// с именем опции; вызывать её нужно в конструкторе, чтобы паника случалась при создании, а не при работе.
+func Typed[F any](v any, name string) F {
+	var zero F
+
+	if v == nil {
+		return zero
+	}
+
+	f, ok := v.(F)
+	if !ok {
+		panic(fmt.Sprintf("%s: got %T, want %T", name, v, zero))
+	}
+
+	return f
+}
@@ -0,0 +1,209 @@
+package workerpool
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type ScaleReason string
+
+const (
+	ScaleUpWait   ScaleReason = "input wait exceeded threshold"
+	ScaleUpQueue  ScaleReason = "queue depth exceeded threshold"
+	ScaleDownIdle ScaleReason = "workers idle"
+)
+
+type ScaleEvent struct {
+	Time   time.Time
+	From   int
+	To     int
+	Reason ScaleReason
+}
+
+type AutoscaleConfig struct {
+	MinWorkers int
+	MaxWorkers int
+
+	// Увеличивать пул, если передача item воркеру ждала дольше порога
+	UpWaitThreshold time.Duration
+	// Увеличивать пул, если в очереди и в ожидании отправки не меньше UpQueueDepth item
+	UpQueueDepth int
+	// Уменьшать пул, если хотя бы один воркер непрерывно простаивал дольше
+	DownIdleTimeout time.Duration
+
+	// На сколько воркеров изменять пул за раз. По умолчанию 1
+	Step int
+	// Сколько проверок подряд должно выполняться условие, прежде чем пул будет изменён. По умолчанию 1
+	Hysteresis int
+	// Минимальная пауза между изменениями
+	Cooldown time.Duration
+	// Период проверки. По умолчанию 100ms
+	Interval time.Duration
+
+	// Вызывается синхронно на каждое изменение размера пула
+	OnEvent func(ScaleEvent)
+}
+
+type AutoscaleStat struct {
+	Workers    int
+	Busy       int
+	ScaleUps   uint64
+	ScaleDowns uint64
+	LastEvent  ScaleEvent
+}
+
+// WithAutoscale включает автомасштабирование. maxworkers из New становится начальным размером.
+func WithAutoscale(cfg AutoscaleConfig) Opt {
+	return func(o *poolOptions) {
+		o.autoscale = &cfg
+	}
+}
+
+type poolLoad struct {
+	workers int
+	busy    int
+	queued  int
+	maxWait time.Duration
+}
+
+type scalable interface {
+	SetWorkersCount(count int)
+	load() poolLoad
+}
+
+type autoscaler struct {
+	cfg  AutoscaleConfig
+	pool scalable
+
+	upStreak   int
+	idleSince  time.Time
+	lastScaled time.Time
+
+	mu   sync.Mutex
+	stat AutoscaleStat
+}
+
+func newAutoscaler(cfg AutoscaleConfig, pool scalable) *autoscaler {
+	// Пул без воркеров останавливается, поэтому меньше одного нельзя
+	cfg.MinWorkers = max(cfg.MinWorkers, 1)
+	cfg.MaxWorkers = max(cfg.MaxWorkers, cfg.MinWorkers)
+	cfg.Step = max(cfg.Step, 1)
+	cfg.Hysteresis = max(cfg.Hysteresis, 1)
+
+	if cfg.Interval <= 0 {
+		cfg.Interval = 100 * time.Millisecond
+	}
+
+	return &autoscaler{cfg: cfg, pool: pool}
+}
+
+func (a *autoscaler) clamp(workers int) int {
+	return min(max(workers, a.cfg.MinWorkers), a.cfg.MaxWorkers)
+}
+
+func (a *autoscaler) run(ctx context.Context) {
+	ticker := time.NewTicker(a.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			a.tick(now)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (a *autoscaler) tick(now time.Time) {
+	l := a.pool.load()
+
+	var upReason ScaleReason
+	switch {
+	case a.cfg.UpWaitThreshold > 0 && l.maxWait > a.cfg.UpWaitThreshold:
+		upReason = ScaleUpWait
+	case a.cfg.UpQueueDepth > 0 && l.queued >= a.cfg.UpQueueDepth:
+		upReason = ScaleUpQueue
+	}
+
+	if upReason != "" {
+		a.upStreak++
+	} else {
+		a.upStreak = 0
+	}
+
+	if l.busy < l.workers {
+		if a.idleSince.IsZero() {
+			a.idleSince = now
+		}
+	} else {
+		a.idleSince = time.Time{}
+	}
+
+	a.mu.Lock()
+	a.stat.Workers = l.workers
+	a.stat.Busy = l.busy
+	a.mu.Unlock()
+
+	if !a.lastScaled.IsZero() && now.Sub(a.lastScaled) < a.cfg.Cooldown {
+		return
+	}
+
+	switch {
+	case a.upStreak >= a.cfg.Hysteresis && l.workers < a.cfg.MaxWorkers:
+		a.scale(now, l.workers, a.clamp(l.workers+a.cfg.Step), upReason)
+	case upReason == "" && a.cfg.DownIdleTimeout > 0 && !a.idleSince.IsZero() &&
+		now.Sub(a.idleSince) >= a.cfg.DownIdleTimeout && l.workers > a.cfg.MinWorkers:
+		a.scale(now, l.workers, a.clamp(l.workers-a.cfg.Step), ScaleDownIdle)
+	}
+}
+
+func (a *autoscaler) scale(now time.Time, from, to int, reason ScaleReason) {
+	a.pool.SetWorkersCount(to)
+
+	a.upStreak = 0
+	a.idleSince = time.Time{}
+	a.lastScaled = now
+
+	ev := ScaleEvent{Time: now, From: from, To: to, Reason: reason}
+
+	a.mu.Lock()
+	if to > from {
+		a.stat.ScaleUps++
+	} else {
+		a.stat.ScaleDowns++
+	}
+	a.stat.Workers = to
+	a.stat.LastEvent = ev
+	a.mu.Unlock()
+
+	if h := a.cfg.OnEvent; h != nil {
+		h(ev)
+	}
+}
+
+func (a *autoscaler) getStat() AutoscaleStat {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.stat
+}
+
+func (w *workerpool[T]) load() poolLoad {
+	return poolLoad{
+		workers: w.WorkersCount(),
+		busy:    int(w.busy.Load()),
+		queued:  int(w.submitting.Load()) + w.QueueLen(),
+		maxWait: time.Duration(w.maxSubmitNs.Swap(0)),
+	}
+}
+
+// AutoscaleStat возвращает состояние автомасштабирования или нулевое значение, если оно выключено.
+func (w *workerpool[T]) AutoscaleStat() AutoscaleStat {
+	if w.scaler == nil {
+		return AutoscaleStat{}
+	}
+
+	return w.scaler.getStat()
+}
@@ -0,0 +1,77 @@
+package workerpool_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SlamJam/go-libs/workerpool"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkerpoolAutoscale(t *testing.T) {
+	t.Parallel()
+
+	var eventsMu sync.Mutex
+	var events []workerpool.ScaleEvent
+
+	release := make(chan struct{})
+
+	w := workerpool.New(func(ctx context.Context, i int) {
+		<-release
+	}, 1, workerpool.WithAutoscale(workerpool.AutoscaleConfig{
+		MinWorkers:      1,
+		MaxWorkers:      4,
+		UpQueueDepth:    1,
+		DownIdleTimeout: 20 * time.Millisecond,
+		Interval:        5 * time.Millisecond,
+		OnEvent: func(ev workerpool.ScaleEvent) {
+			eventsMu.Lock()
+			defer eventsMu.Unlock()
+
+			events = append(events, ev)
+		},
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	w.Start(ctx)
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, w.Submit(ctx, i))
+		}()
+	}
+
+	assert.Eventually(t, func() bool {
+		return w.WorkersCount() == 4
+	}, time.Second, time.Millisecond)
+
+	close(release)
+	wg.Wait()
+
+	assert.Eventually(t, func() bool {
+		return w.WorkersCount() == 1
+	}, time.Second, time.Millisecond)
+
+	stat := w.AutoscaleStat()
+	assert.Equal(t, uint64(3), stat.ScaleUps)
+	assert.Equal(t, uint64(3), stat.ScaleDowns)
+	assert.Equal(t, workerpool.ScaleDownIdle, stat.LastEvent.Reason)
+
+	eventsMu.Lock()
+	assert.Len(t, events, 6)
+	assert.Equal(t, workerpool.ScaleUpQueue, events[0].Reason)
+	eventsMu.Unlock()
+
+	err := w.Interrupt(ctx)
+	assert.NoError(t, err)
+
+	err = w.WaitUntilHalted(ctx)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,320 @@
+package workerpool
+
+import (
+	"context"
+	"sync"
+
+	std "github.com/SlamJam/go-libs"
+	"github.com/SlamJam/go-libs/xchan"
+	"github.com/pkg/errors"
+)
+
+var ErrUnknownClass = errors.New("unknown priority class")
+
+// FairConfig описывает очередь с классами приоритета.
+// Классы обслуживаются строго по приоритету: 0 - самый важный, пока в нём есть item, младшие ждут.
+// Внутри класса item разных ключей (например, тенантов) чередуются взвешенным round robin,
+// поэтому поток одного ключа не вытесняет остальные.
+type FairConfig struct {
+	Classes int
+	// Ёмкость каждого класса, 0 - без ограничения. При заполнении отправитель ждёт
+	ClassCap int
+	// Сколько item ключ может отдать подряд за один круг. По умолчанию 1
+	Weights map[string]int
+}
+
+type ClassStat struct {
+	Depth      int
+	Keys       int
+	Dispatched uint64
+}
+
+// WithFairQueue включает очередь с приоритетами и справедливым чередованием ключей (см. SubmitFair).
+// Submit без класса попадает в самый младший класс с пустым ключом. Несовместима с WithQueue.
+func WithFairQueue(cfg FairConfig) Opt {
+	return func(o *poolOptions) {
+		o.fair = &cfg
+	}
+}
+
+type fairKey[T any] struct {
+	items  []T
+	credit int
+}
+
+type fairClass[T any] struct {
+	keys map[string]*fairKey[T]
+	// Ключи с непустой очередью в порядке обхода
+	ring       []string
+	pos        int
+	depth      int
+	dispatched uint64
+}
+
+type fairQueue[T any] struct {
+	mu       sync.Mutex
+	classCap int
+	weights  map[string]int
+	classes  []*fairClass[T]
+	// Сигнал единственному потребителю о новом item
+	pushed chan struct{}
+	// Закрывается и пересоздаётся при каждом извлечении, чтобы разбудить ждущих места отправителей
+	popped chan struct{}
+}
+
+func newFairQueue[T any](cfg FairConfig) *fairQueue[T] {
+	std.AssertSize(cfg.Classes)
+
+	q := &fairQueue[T]{
+		classCap: cfg.ClassCap,
+		weights:  map[string]int{},
+		classes:  make([]*fairClass[T], cfg.Classes),
+		pushed:   make(chan struct{}, 1),
+		popped:   make(chan struct{}),
+	}
+
+	for k, v := range cfg.Weights {
+		q.weights[k] = v
+	}
+
+	for i := range q.classes {
+		q.classes[i] = &fairClass[T]{keys: map[string]*fairKey[T]{}}
+	}
+
+	return q
+}
+
+func (q *fairQueue[T]) lowest() int {
+	return len(q.classes) - 1
+}
+
+func (q *fairQueue[T]) setWeight(key string, weight int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.weights[key] = weight
+}
+
+func (q *fairQueue[T]) weight(key string) int {
+	if w, ok := q.weights[key]; ok && w > 0 {
+		return w
+	}
+
+	return 1
+}
+
+// tryPush возвращает канал, которого нужно дождаться, если класс заполнен.
+func (q *fairQueue[T]) tryPush(class int, key string, item T) (wait <-chan struct{}) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	c := q.classes[class]
+	if q.classCap > 0 && c.depth >= q.classCap {
+		return q.popped
+	}
+
+	k, ok := c.keys[key]
+	if !ok {
+		k = &fairKey[T]{}
+		c.keys[key] = k
+		c.ring = append(c.ring, key)
+	}
+
+	k.items = append(k.items, item)
+	c.depth++
+
+	xchan.TrySendNonBlocking(q.pushed, struct{}{})
+
+	return nil
+}
+
+// push ставит item в очередь. Ожидание места прерывается ctx или закрытием stop, тогда возвращается stopErr().
+func (q *fairQueue[T]) push(ctx context.Context, class int, key string, item T, wait bool, stop <-chan struct{}, stopErr func() error) error {
+	if class < 0 || class >= len(q.classes) {
+		return errors.Wrapf(ErrUnknownClass, "%d", class)
+	}
+
+	for {
+		popped := q.tryPush(class, key, item)
+		if popped == nil {
+			return nil
+		}
+
+		if !wait {
+			return ErrQueueFull
+		}
+
+		select {
+		case <-popped:
+		case <-stop:
+			return stopErr()
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (q *fairQueue[T]) tryPop() (T, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, c := range q.classes {
+		if c.depth == 0 {
+			continue
+		}
+
+		key := c.ring[c.pos]
+		k := c.keys[key]
+
+		if k.credit == 0 {
+			k.credit = q.weight(key)
+		}
+
+		item := k.items[0]
+		k.items[0] = std.Zero[T]()
+		k.items = k.items[1:]
+		k.credit--
+
+		c.depth--
+		c.dispatched++
+
+		switch {
+		case len(k.items) == 0:
+			delete(c.keys, key)
+			c.ring = append(c.ring[:c.pos], c.ring[c.pos+1:]...)
+			if c.pos >= len(c.ring) {
+				c.pos = 0
+			}
+		case k.credit == 0:
+			c.pos = (c.pos + 1) % len(c.ring)
+		}
+
+		close(q.popped)
+		q.popped = make(chan struct{})
+
+		return item, true
+	}
+
+	return std.Zero[T](), false
+}
+
+// pop ждёт item, пока не отменён ctx. После закрытия stop возвращает только уже стоящие в очереди item.
+func (q *fairQueue[T]) pop(ctx context.Context, stop <-chan struct{}) (T, bool) {
+	for {
+		if item, ok := q.tryPop(); ok {
+			return item, true
+		}
+
+		select {
+		case <-q.pushed:
+		case <-stop:
+			return std.Zero[T](), false
+		case <-ctx.Done():
+			return std.Zero[T](), false
+		}
+	}
+}
+
+func (q *fairQueue[T]) drain() []T {
+	var result []T
+	for {
+		item, ok := q.tryPop()
+		if !ok {
+			return result
+		}
+
+		result = append(result, item)
+	}
+}
+
+func (q *fairQueue[T]) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var result int
+	for _, c := range q.classes {
+		result += c.depth
+	}
+
+	return result
+}
+
+func (q *fairQueue[T]) stats() []ClassStat {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	result := make([]ClassStat, 0, len(q.classes))
+	for _, c := range q.classes {
+		result = append(result, ClassStat{
+			Depth:      c.depth,
+			Keys:       len(c.keys),
+			Dispatched: c.dispatched,
+		})
+	}
+
+	return result
+}
+
+// dispatch передаёт item из очереди воркерам, пока не отменён ctx или пока очередь не опустеет после Shutdown.
+func (w *workerpool[T]) dispatch(ctx context.Context) {
+	defer close(w.dispatched)
+
+	for {
+		item, ok := w.fair.pop(ctx, w.closing)
+		if !ok {
+			return
+		}
+
+		select {
+		case w.Input <- item:
+		case <-ctx.Done():
+			w.strand(item)
+			return
+		}
+	}
+}
+
+// SubmitFair ставит item в класс приоритета class от имени ключа key.
+// Требует WithFairQueue. Если класс заполнен, ждёт места, пока не отменён ctx.
+func (w *workerpool[T]) SubmitFair(ctx context.Context, class int, key string, item T) error {
+	if w.fair == nil {
+		return errors.Wrap(ErrUnknownClass, "fair queue is disabled")
+	}
+
+	err := w.submitFair(ctx, class, key, item)
+
+	res := Enqueued
+	if err != nil {
+		res = Rejected
+	}
+	w.metrics.onSubmit(res, err)
+
+	return err
+}
+
+func (w *workerpool[T]) submitFair(ctx context.Context, class int, key string, item T) error {
+	w.submitMu.RLock()
+	defer w.submitMu.RUnlock()
+
+	if err := w.acceptErr(); err != nil {
+		return err
+	}
+
+	return w.fair.push(ctx, class, key, item, true, w.rejecting, w.submitErr)
+}
+
+// SetKeyWeight меняет вес ключа в очереди с приоритетами.
+func (w *workerpool[T]) SetKeyWeight(key string, weight int) {
+	if w.fair != nil {
+		w.fair.setWeight(key, weight)
+	}
+}
+
+// ClassStats возвращает состояние классов очереди с приоритетами или nil, если она выключена.
+func (w *workerpool[T]) ClassStats() []ClassStat {
+	if w.fair == nil {
+		return nil
+	}
+
+	return w.fair.stats()
+}
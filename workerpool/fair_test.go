@@ -0,0 +1,69 @@
+package workerpool_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/SlamJam/go-libs/workerpool"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkerpoolFairQueue(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	processed := make(chan string, 16)
+
+	w := workerpool.New(func(ctx context.Context, s string) {
+		if s == "blocker" {
+			close(started)
+			<-release
+		}
+		processed <- s
+	}, 1, workerpool.WithFairQueue(workerpool.FairConfig{
+		Classes: 2,
+		Weights: map[string]int{"a": 2},
+	}))
+
+	w.Start(ctx)
+
+	assert.NoError(t, w.SubmitFair(ctx, 0, "", "blocker"))
+	<-started
+
+	// Диспетчер забирает следующий item и ждёт свободного воркера, порядок решается после него
+	assert.NoError(t, w.SubmitFair(ctx, 1, "", "in-hand"))
+	assert.Eventually(t, func() bool {
+		return w.ClassStats()[1].Dispatched == 1
+	}, time.Second, time.Millisecond)
+
+	for _, s := range []string{"b1", "b2", "b3"} {
+		assert.NoError(t, w.SubmitFair(ctx, 1, "b", s))
+	}
+	for _, s := range []string{"a1", "a2", "a3"} {
+		assert.NoError(t, w.SubmitFair(ctx, 0, "a", s))
+	}
+	for _, s := range []string{"c1", "c2"} {
+		assert.NoError(t, w.SubmitFair(ctx, 0, "c", s))
+	}
+
+	stats := w.ClassStats()
+	assert.Equal(t, workerpool.ClassStat{Depth: 5, Keys: 2, Dispatched: 1}, stats[0])
+	assert.Equal(t, workerpool.ClassStat{Depth: 3, Keys: 1, Dispatched: 1}, stats[1])
+
+	err := w.SubmitFair(ctx, 2, "", "unknown")
+	assert.ErrorIs(t, err, workerpool.ErrUnknownClass)
+
+	close(release)
+
+	var got []string
+	for range 10 {
+		got = append(got, <-processed)
+	}
+
+	assert.Equal(t, []string{"blocker", "in-hand", "a1", "a2", "c1", "a3", "c2", "b1", "b2", "b3"}, got)
+}
@@ -0,0 +1,79 @@
+package workerpool_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/SlamJam/go-libs/workerpool"
+	"github.com/stretchr/testify/assert"
+)
+
+// blockedPool - пул из одного воркера, занятого item 0, пока не закрыт release, с item 1 и 2 в очереди на два места.
+type blockedPool struct {
+	pool interface {
+		SubmitContext(context.Context, int) (workerpool.SubmitResult, error)
+		TrySubmit(int) (workerpool.SubmitResult, error)
+		QueueLen() int
+		Shutdown(context.Context, bool) ([]int, error)
+		IsHalted() bool
+	}
+	release chan struct{}
+	// Обработанные item в порядке обработки
+	processed chan int
+}
+
+func newBlockedPool(t *testing.T, ctx context.Context, policy workerpool.QueuePolicy) *blockedPool {
+	t.Helper()
+
+	started := make(chan struct{})
+	b := &blockedPool{
+		release:   make(chan struct{}),
+		processed: make(chan int, 8),
+	}
+
+	pool := workerpool.New(func(ctx context.Context, i int) {
+		if i == 0 {
+			close(started)
+			select {
+			case <-b.release:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		b.processed <- i
+	}, 1, workerpool.WithQueue(2, policy))
+
+	pool.Start(ctx)
+
+	res, err := pool.SubmitContext(ctx, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, workerpool.Enqueued, res)
+	<-started
+
+	for i := 1; i <= 2; i++ {
+		res, err := pool.TrySubmit(i)
+		assert.NoError(t, err)
+		assert.Equal(t, workerpool.Enqueued, res)
+	}
+
+	assert.Equal(t, 2, pool.QueueLen())
+
+	b.pool = pool
+
+	return b
+}
+
+// takeProcessed возвращает item, обработанные к этому моменту.
+func (b *blockedPool) takeProcessed() []int {
+	var result []int
+
+	for {
+		select {
+		case i := <-b.processed:
+			result = append(result, i)
+		default:
+			return result
+		}
+	}
+}
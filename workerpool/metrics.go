@@ -0,0 +1,252 @@
+package workerpool
+
+import (
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/SlamJam/go-libs/actors"
+	"github.com/SlamJam/go-libs/xgo"
+	"github.com/pkg/errors"
+)
+
+// Outcome - чем закончилось выполнение item.
+type Outcome int
+
+const (
+	OutcomeCompleted Outcome = iota
+	// f вернула ошибку (см. NewWithResult)
+	OutcomeFailed
+	// Все попытки закончились паникой
+	OutcomePanicked
+	// Истёк таймаут item (см. TimeoutError)
+	OutcomeTimedOut
+)
+
+// Metrics получает события пула, чтобы передавать их во внешнюю систему метрик.
+// Методы вызываются из отправителей и воркеров, поэтому должны быть потокобезопасными и не блокироваться.
+// Текущие значения (глубину очереди, занятых воркеров) удобно снимать через GetStat.
+type Metrics interface {
+	Submitted(res SubmitResult, err error)
+	Finished(d time.Duration, outcome Outcome)
+}
+
+// Histogram - распределение длительностей.
+// Counts[i] - число значений не больше Bounds[i], последний элемент Counts - всё, что больше Bounds[len-1].
+type Histogram struct {
+	Bounds []time.Duration
+	Counts []uint64
+	Count  uint64
+	Sum    time.Duration
+}
+
+// Stat - статистика пула.
+type Stat struct {
+	// Item, принятые пулом
+	Submitted uint64
+	// Отправки, завершившиеся ошибкой
+	Rejected uint64
+	// Item, отброшенные политикой очереди или оставшиеся в очереди после прерывания пула
+	Dropped uint64
+
+	Completed uint64
+	Failed    uint64
+	Panicked  uint64
+	TimedOut  uint64
+	// Воркеры, брошенные по WithAbandonAfter
+	Abandoned uint64
+
+	// Выполняемые item, включая выполняемые отправителем по QueueCallerRuns
+	InFlight   int
+	QueueDepth int
+	Workers    int
+	Busy       int
+	Idle       int
+
+	Durations Histogram
+}
+
+var DefaultDurationBuckets = []time.Duration{
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// WithMetrics передаёт события пула в m.
+func WithMetrics(m Metrics) Opt {
+	return func(o *poolOptions) {
+		o.metrics = m
+	}
+}
+
+// WithDurationBuckets задаёт границы корзин гистограммы длительностей. По умолчанию DefaultDurationBuckets.
+func WithDurationBuckets(bounds ...time.Duration) Opt {
+	return func(o *poolOptions) {
+		o.durationBuckets = bounds
+	}
+}
+
+var (
+	_ actors.ActorWithStat[Stat] = (*workerpool[int])(nil)
+	_ actors.ActorWithStat[Stat] = (*resultWorkerpool[int, int])(nil)
+	_ actors.ActorWithStat[Stat] = (*partitionedWorkerpool[int])(nil)
+)
+
+// finishOnlyMetrics передаёт только события выполнения, когда об отправках сообщает обёртка над пулом.
+type finishOnlyMetrics struct {
+	Metrics
+}
+
+func (m finishOnlyMetrics) Submitted(SubmitResult, error) {}
+
+func (m finishOnlyMetrics) Finished(d time.Duration, outcome Outcome) {
+	if m.Metrics != nil {
+		m.Metrics.Finished(d, outcome)
+	}
+}
+
+type poolMetrics struct {
+	submitted atomic.Uint64
+	rejected  atomic.Uint64
+	dropped   atomic.Uint64
+	completed atomic.Uint64
+	failed    atomic.Uint64
+	panicked  atomic.Uint64
+	timedOut  atomic.Uint64
+	abandoned atomic.Uint64
+	inFlight  atomic.Int32
+
+	bounds  []time.Duration
+	buckets []atomic.Uint64
+	sumNs   atomic.Int64
+
+	sink Metrics
+}
+
+func newPoolMetrics(o poolOptions) *poolMetrics {
+	bounds := o.durationBuckets
+	if bounds == nil {
+		bounds = DefaultDurationBuckets
+	}
+
+	bounds = append([]time.Duration(nil), bounds...)
+	sort.Slice(bounds, func(i, j int) bool { return bounds[i] < bounds[j] })
+
+	return &poolMetrics{
+		bounds:  bounds,
+		buckets: make([]atomic.Uint64, len(bounds)+1),
+		sink:    o.metrics,
+	}
+}
+
+func (m *poolMetrics) onSubmit(res SubmitResult, err error) {
+	switch {
+	case err != nil:
+		m.rejected.Add(1)
+	case res == DroppedNewest:
+		m.dropped.Add(1)
+	default:
+		m.submitted.Add(1)
+	}
+
+	if m.sink != nil {
+		m.sink.Submitted(res, err)
+	}
+}
+
+func (m *poolMetrics) onDrop() {
+	m.dropped.Add(1)
+}
+
+func outcomeOf(err error) Outcome {
+	switch {
+	case err == nil:
+		return OutcomeCompleted
+	case errors.As(err, new(xgo.PanicError)):
+		return OutcomePanicked
+	case errors.As(err, new(*TimeoutError)):
+		return OutcomeTimedOut
+	default:
+		return OutcomeFailed
+	}
+}
+
+// run выполняет f и учитывает её длительность и исход.
+func (m *poolMetrics) run(f func() error) error {
+	m.inFlight.Add(1)
+	defer m.inFlight.Add(-1)
+
+	startedAt := time.Now()
+	err := f()
+	m.onFinish(time.Since(startedAt), outcomeOf(err))
+
+	return err
+}
+
+func (m *poolMetrics) onFinish(d time.Duration, outcome Outcome) {
+	switch outcome {
+	case OutcomeCompleted:
+		m.completed.Add(1)
+	case OutcomeFailed:
+		m.failed.Add(1)
+	case OutcomePanicked:
+		m.panicked.Add(1)
+	case OutcomeTimedOut:
+		m.timedOut.Add(1)
+	}
+
+	idx := sort.Search(len(m.bounds), func(i int) bool { return d <= m.bounds[i] })
+	m.buckets[idx].Add(1)
+	m.sumNs.Add(int64(d))
+
+	if m.sink != nil {
+		m.sink.Finished(d, outcome)
+	}
+}
+
+func (m *poolMetrics) fill(st *Stat) {
+	st.Submitted = m.submitted.Load()
+	st.Rejected = m.rejected.Load()
+	st.Dropped = m.dropped.Load()
+	st.Completed = m.completed.Load()
+	st.Failed = m.failed.Load()
+	st.Panicked = m.panicked.Load()
+	st.TimedOut = m.timedOut.Load()
+	st.Abandoned = m.abandoned.Load()
+	st.InFlight = int(m.inFlight.Load())
+
+	st.Durations = Histogram{
+		Bounds: append([]time.Duration(nil), m.bounds...),
+		Counts: make([]uint64, len(m.buckets)),
+		Sum:    time.Duration(m.sumNs.Load()),
+	}
+
+	for i := range m.buckets {
+		st.Durations.Counts[i] = m.buckets[i].Load()
+		st.Durations.Count += st.Durations.Counts[i]
+	}
+}
+
+// GetStat возвращает снимок статистики пула.
+func (w *workerpool[T]) GetStat() Stat {
+	var st Stat
+	w.metrics.fill(&st)
+
+	st.Workers = w.WorkersCount()
+	st.Busy = int(w.busy.Load())
+	st.Idle = max(st.Workers-st.Busy, 0)
+	st.QueueDepth = w.QueueLen()
+
+	return st
+}
+
+func (w *workerpool[T]) ViewStat(f func(*Stat)) {
+	st := w.GetStat()
+	f(&st)
+}
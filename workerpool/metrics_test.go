@@ -0,0 +1,170 @@
+package workerpool_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SlamJam/go-libs/workerpool"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingMetrics struct {
+	mu        sync.Mutex
+	submitted []workerpool.SubmitResult
+	outcomes  []workerpool.Outcome
+}
+
+func (m *recordingMetrics) Submitted(res workerpool.SubmitResult, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.submitted = append(m.submitted, res)
+}
+
+func (m *recordingMetrics) Finished(d time.Duration, outcome workerpool.Outcome) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.outcomes = append(m.outcomes, outcome)
+}
+
+func TestWorkerpoolStat(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	m := &recordingMetrics{}
+
+	w := workerpool.NewWithResult(func(ctx context.Context, i int) (int, error) {
+		switch i {
+		case 0:
+			started <- struct{}{}
+			<-release
+		case 1:
+			return 0, errors.New("failed")
+		case 2:
+			panic("boom")
+		}
+
+		return i, nil
+	}, 1,
+		workerpool.WithQueue(1, workerpool.QueueReject),
+		workerpool.WithMetrics(m),
+		workerpool.WithDurationBuckets(time.Hour, time.Millisecond),
+	)
+
+	w.Start(ctx)
+
+	p0 := w.Submit(ctx, 0)
+	<-started
+
+	p1 := w.Submit(ctx, 1)
+
+	_, res := w.TrySubmit(3)
+	assert.Equal(t, workerpool.Rejected, res)
+
+	st := w.GetStat()
+	assert.Equal(t, uint64(2), st.Submitted)
+	assert.Equal(t, uint64(1), st.Rejected)
+	assert.Equal(t, 1, st.InFlight)
+	assert.Equal(t, 1, st.Busy)
+	assert.Equal(t, 0, st.Idle)
+	assert.Equal(t, 1, st.QueueDepth)
+
+	close(release)
+
+	_, err := p0.Poll(ctx)
+	assert.NoError(t, err)
+	_, err = p1.Poll(ctx)
+	assert.Error(t, err)
+	_, err = w.Submit(ctx, 2).Poll(ctx)
+	assert.Error(t, err)
+
+	assert.Eventually(t, func() bool {
+		return w.GetStat().Durations.Count == 3
+	}, time.Second, time.Millisecond)
+
+	w.ViewStat(func(st *workerpool.Stat) {
+		assert.Equal(t, uint64(1), st.Completed)
+		assert.Equal(t, uint64(1), st.Failed)
+		assert.Equal(t, uint64(1), st.Panicked)
+		assert.Equal(t, 0, st.InFlight)
+		assert.Equal(t, []time.Duration{time.Millisecond, time.Hour}, st.Durations.Bounds)
+		assert.Len(t, st.Durations.Counts, 3)
+		assert.Equal(t, uint64(3), st.Durations.Counts[0]+st.Durations.Counts[1])
+	})
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	assert.Equal(t, []workerpool.SubmitResult{workerpool.Enqueued, workerpool.Enqueued, workerpool.Rejected, workerpool.Enqueued}, m.submitted)
+	assert.ElementsMatch(t, []workerpool.Outcome{workerpool.OutcomeCompleted, workerpool.OutcomeFailed, workerpool.OutcomePanicked}, m.outcomes)
+}
+
+func TestWorkerpoolStatBoundsCopied(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	w := workerpool.NewWithResult(func(ctx context.Context, d time.Duration) (int, error) {
+		return 0, nil
+	}, 1, workerpool.WithDurationBuckets(time.Hour, 2*time.Hour))
+
+	w.Start(ctx)
+
+	// Изменение снимка не должно влиять на распределение по корзинам
+	st := w.GetStat()
+	st.Durations.Bounds[0] = 0
+
+	_, err := w.Submit(ctx, 0).Poll(ctx)
+	assert.NoError(t, err)
+
+	st = w.GetStat()
+	assert.Equal(t, []time.Duration{time.Hour, 2 * time.Hour}, st.Durations.Bounds)
+	assert.Equal(t, []uint64{1, 0, 0}, st.Durations.Counts)
+}
+
+func TestPartitionedStat(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	m := &recordingMetrics{}
+
+	w := workerpool.NewPartitioned(func(ctx context.Context, i int) {
+		if i == 3 {
+			panic("boom")
+		}
+	}, func(i int) string { return "" }, 2, 1, workerpool.WithMetrics(m))
+
+	w.Start(ctx)
+
+	for i := range 4 {
+		assert.NoError(t, w.Submit(ctx, i))
+	}
+
+	assert.Eventually(t, func() bool {
+		st := w.GetStat()
+		return st.Completed+st.Panicked == 4
+	}, time.Second, time.Millisecond)
+
+	st := w.GetStat()
+	assert.Equal(t, uint64(4), st.Submitted)
+	assert.Equal(t, uint64(3), st.Completed)
+	assert.Equal(t, uint64(1), st.Panicked)
+	assert.Equal(t, 0, st.QueueDepth)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	assert.Len(t, m.submitted, 4)
+	assert.Len(t, m.outcomes, 4)
+}
@@ -0,0 +1,139 @@
+package workerpool
+
+import (
+	"context"
+
+	"github.com/SlamJam/go-libs/options"
+	"github.com/SlamJam/go-libs/xchan"
+	"github.com/SlamJam/go-libs/xgo"
+)
+
+type Panic[T any] struct {
+	Item  T
+	Panic any
+	Stack []byte
+	// Сколько раз item запускался, включая повторы
+	Attempts int
+}
+
+// WithPanicStream включает поток паник Panics размером size.
+// Если поток заполнен, новые паники в него не попадают.
+func WithPanicStream(size int) Opt {
+	return func(o *poolOptions) {
+		o.panicStreamCap = size
+	}
+}
+
+// WithPanicHandler задаёт обработчик паник. Тип T должен совпадать с типом item пула.
+func WithPanicHandler[T any](h func(Panic[T])) Opt {
+	return func(o *poolOptions) {
+		o.panicHandler = h
+	}
+}
+
+// WithPanicRetries повторяет запуск item после паники до retries раз, прежде чем сообщить о ней.
+func WithPanicRetries(retries int) Opt {
+	return func(o *poolOptions) {
+		o.panicRetries = retries
+	}
+}
+
+// withoutPanicReporting отключает обработчик и поток паник, когда о них сообщает обёртка над пулом.
+func withoutPanicReporting() Opt {
+	return func(o *poolOptions) {
+		o.panicHandler = nil
+		o.panicStreamCap = 0
+	}
+}
+
+type panicReporter[T any] struct {
+	handler func(Panic[T])
+	stream  chan Panic[T]
+}
+
+func newPanicReporter[T any](o poolOptions) panicReporter[T] {
+	r := panicReporter[T]{
+		handler: options.Typed[func(Panic[T])](o.panicHandler, "workerpool: WithPanicHandler"),
+	}
+
+	if o.panicStreamCap > 0 {
+		r.stream = make(chan Panic[T], o.panicStreamCap)
+	}
+
+	return r
+}
+
+func (r panicReporter[T]) report(p Panic[T]) {
+	if r.handler != nil {
+		_ = xgo.CatchPanic(func() { r.handler(p) })
+	}
+
+	if r.stream != nil {
+		xchan.TrySendNonBlocking(r.stream, p)
+	}
+}
+
+// runWithRetries выполняет f, повторяя её после паники до retries раз, пока не отменён ctx.
+// Возвращает последнюю панику или nil и число попыток.
+func runWithRetries(ctx context.Context, retries int, f func()) (p *xgo.PanicError, attempts int) {
+	for attempts <= retries {
+		attempts++
+
+		if p = xgo.CatchPanic(f); p == nil || ctx.Err() != nil {
+			break
+		}
+	}
+
+	return p, attempts
+}
+
+// process выполняет item и возвращает true, если воркер нужно заменить:
+// все попытки закончились паникой или воркер брошен по WithAbandonAfter.
+func (w *workerpool[T]) process(item T) (replace bool) {
+	ctx, cancel := w.jobContext(item)
+	defer cancel()
+
+	var p *xgo.PanicError
+	var attempts int
+	var abandoned bool
+
+	err := w.metrics.run(func() error {
+		var err error
+		run := func() {
+			p, attempts = runWithRetries(ctx, w.opts.panicRetries, func() { err = w.f(ctx, item) })
+		}
+
+		// После того как воркер брошен, p и err пишет только брошенная горутина, здесь они больше не читаются
+		if abandoned = !w.runOrAbandon(ctx, run); abandoned {
+			return &TimeoutError{Abandoned: true, Cause: ctx.Err()}
+		}
+
+		if p != nil {
+			return *p
+		}
+
+		return err
+	})
+
+	if abandoned {
+		w.metrics.abandoned.Add(1)
+		if w.onAbandon != nil {
+			w.onAbandon(item, err)
+		}
+
+		return true
+	}
+
+	if p == nil {
+		return false
+	}
+
+	report := Panic[T]{Item: item, Panic: p.Payload, Stack: p.Stack(), Attempts: attempts}
+
+	if w.onPanic != nil {
+		w.onPanic(report)
+	}
+	w.panics.report(report)
+
+	return true
+}
@@ -0,0 +1,134 @@
+package workerpool_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/SlamJam/go-libs/workerpool"
+	"github.com/SlamJam/go-libs/xgo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkerpoolPanics(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls sync.Map
+	var handled atomic.Int32
+	var processed atomic.Int32
+
+	w := workerpool.New(func(ctx context.Context, i int) {
+		n, _ := calls.LoadOrStore(i, new(atomic.Int32))
+		attempt := n.(*atomic.Int32).Add(1)
+
+		switch {
+		case i%2 == 1:
+			panic(i)
+		case i == 2 && attempt == 1:
+			// Первая попытка падает, повтор проходит
+			panic(i)
+		}
+
+		processed.Add(1)
+	}, 2,
+		workerpool.WithPanicStream(8),
+		workerpool.WithPanicRetries(1),
+		workerpool.WithPanicHandler(func(p workerpool.Panic[int]) {
+			handled.Add(1)
+		}),
+	)
+
+	w.Start(ctx)
+
+	for i := range 6 {
+		assert.NoError(t, w.Submit(ctx, i))
+	}
+
+	for range 3 {
+		p := <-w.Panics
+		assert.Equal(t, 1, p.Item%2)
+		assert.Equal(t, p.Item, p.Panic)
+		assert.Equal(t, 2, p.Attempts)
+		assert.NotEmpty(t, p.Stack)
+	}
+
+	assert.Eventually(t, func() bool {
+		return processed.Load() == 3
+	}, time.Second, time.Millisecond)
+
+	assert.Equal(t, int32(3), handled.Load())
+	assert.Len(t, w.Panics, 0)
+
+	// Упавшие воркеры заменены
+	assert.Eventually(t, func() bool {
+		return w.WorkersCount() == 2
+	}, time.Second, time.Millisecond)
+}
+
+func TestWorkerpoolWithResultPanics(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	w := workerpool.NewWithResult(func(ctx context.Context, s string) (int, error) {
+		panic("boom: " + s)
+	}, 1, workerpool.WithPanicStream(1))
+
+	w.Start(ctx)
+
+	_, err := w.Submit(ctx, "x").Poll(ctx)
+
+	var panicErr xgo.PanicError
+	if assert.ErrorAs(t, err, &panicErr) {
+		assert.Equal(t, "boom: x", panicErr.Payload)
+	}
+
+	p := <-w.Panics()
+	assert.Equal(t, "x", p.Item)
+	assert.Equal(t, 1, p.Attempts)
+}
+
+func TestWorkerpoolPanicHandlerTypeMismatch(t *testing.T) {
+	t.Parallel()
+
+	assert.Panics(t, func() {
+		workerpool.New(func(ctx context.Context, i int) {}, 1,
+			workerpool.WithPanicHandler(func(p workerpool.Panic[string]) {}),
+		)
+	})
+}
+
+func TestWorkerpoolSingleWorkerSurvivesPanic(t *testing.T) {
+	t.Parallel()
+
+	for range 50 {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		w := workerpool.NewWithResult(func(ctx context.Context, i int) (int, error) {
+			if i == 0 {
+				panic("boom")
+			}
+
+			return i, nil
+		}, 1)
+
+		w.Start(ctx)
+
+		_, err := w.Submit(ctx, 0).Poll(ctx)
+		assert.ErrorAs(t, err, &xgo.PanicError{})
+
+		// Замена упавшего воркера не даёт пулу остановиться
+		v, err := w.Submit(ctx, 1).Poll(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, v)
+		assert.False(t, w.IsHalted())
+
+		cancel()
+	}
+}
@@ -0,0 +1,268 @@
+package workerpool
+
+import (
+	"context"
+	"hash/maphash"
+	"sync"
+
+	std "github.com/SlamJam/go-libs"
+	"github.com/SlamJam/go-libs/actors"
+	"github.com/SlamJam/go-libs/options"
+	"github.com/SlamJam/go-libs/xchan"
+)
+
+type partition[T any] struct {
+	mu    sync.Mutex
+	items []T
+	// Партиция стоит в очереди пула или обрабатывается воркером
+	active  bool
+	running bool
+}
+
+type partitionedWorkerpool[T any] struct {
+	actors.Actor
+
+	f          func(context.Context, T)
+	key        func(T) string
+	seed       maphash.Seed
+	partitions []*partition[T]
+	pool       *workerpool[int]
+	retries    int
+	panics     panicReporter[T]
+	metrics    *poolMetrics
+
+	submitMu sync.RWMutex
+	closing  bool
+	// Сигнал о том, что партиция закончила item, чтобы Shutdown перепроверил Backlog
+	idle chan struct{}
+}
+
+// NewPartitioned создаёт пул, в котором item с одинаковым ключом обрабатываются строго последовательно
+// и в порядке отправки, а item разных ключей - параллельно.
+// Ключ хешируется в одну из partitions партиций; партиция одновременно обрабатывается не более чем одним воркером,
+// поэтому число воркеров можно менять через SetWorkersCount, не нарушая порядок внутри ключа.
+func NewPartitioned[T any](f func(context.Context, T), key func(T) string, partitions, workers int, opts ...Opt) *partitionedWorkerpool[T] {
+	std.AssertSize(partitions)
+
+	w := &partitionedWorkerpool[T]{
+		f:          f,
+		key:        key,
+		seed:       maphash.MakeSeed(),
+		partitions: make([]*partition[T], partitions),
+		idle:       make(chan struct{}, 1),
+	}
+
+	for i := range w.partitions {
+		w.partitions[i] = &partition[T]{}
+	}
+
+	optState := options.Create(opts...)
+	// Пул планирует партиции, а не item, поэтому классы и ключи очереди с приоритетами к ним неприменимы
+	if optState.fair != nil {
+		panic("workerpool: partitioned pool does not support WithFairQueue")
+	}
+
+	w.retries = optState.panicRetries
+	w.panics = newPanicReporter[T](optState)
+
+	// Каждая партиция находится в очереди не более одного раза,
+	// поэтому очереди на partitions мест достаточно, чтобы повторная постановка никогда не блокировалась
+	w.metrics = newPoolMetrics(optState)
+
+	w.pool = newWorkerpool(w.runPartition, workers, append(opts[:len(opts):len(opts)],
+		WithQueue(partitions, QueueBlock),
+		// Паники item обрабатываются здесь: повтор на уровне пула взял бы из партиции следующий item
+		WithPanicRetries(0),
+		withoutPanicReporting(),
+		// Пул видит отправки партиций, а не item, поэтому отправки учитываются здесь
+		WithMetrics(finishOnlyMetrics{optState.metrics}),
+		withPartitionRateKey(w, optState),
+	)...)
+	// Партиции, оставшиеся в очереди остановленного пула, больше никто не запланирует
+	w.pool.onReject = func(idx int, _ error) {
+		p := w.partitions[idx]
+
+		p.mu.Lock()
+		p.active = false
+		p.mu.Unlock()
+	}
+
+	w.Actor = w.pool.Actor
+
+	return w
+}
+
+// withPartitionRateKey ограничивает темп по ключу item, который партиция обработает следующим.
+func withPartitionRateKey[T any](w *partitionedWorkerpool[T], o poolOptions) Opt {
+	key := options.Typed[func(T) string](o.rateKey, "workerpool: WithKeyRateLimit")
+
+	return withRateKey(func(idx int) string {
+		p := w.partitions[idx]
+
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		if len(p.items) == 0 {
+			return ""
+		}
+
+		return key(p.items[0])
+	})
+}
+
+// PartitionOf возвращает номер партиции для ключа.
+func (w *partitionedWorkerpool[T]) PartitionOf(key string) int {
+	return int(maphash.String(w.seed, key) % uint64(len(w.partitions)))
+}
+
+// Submit добавляет item в конец партиции его ключа. Не блокируется.
+func (w *partitionedWorkerpool[T]) Submit(ctx context.Context, item T) error {
+	err := w.submit(ctx, item)
+
+	res := Enqueued
+	if err != nil {
+		res = Rejected
+	}
+	w.metrics.onSubmit(res, err)
+
+	return err
+}
+
+func (w *partitionedWorkerpool[T]) submit(ctx context.Context, item T) error {
+	w.submitMu.RLock()
+	defer w.submitMu.RUnlock()
+
+	if w.closing {
+		return ErrShutdown
+	}
+
+	if err := w.pool.acceptErr(); err != nil {
+		return err
+	}
+
+	idx := w.PartitionOf(w.key(item))
+	p := w.partitions[idx]
+
+	p.mu.Lock()
+	p.items = append(p.items, item)
+	schedule := !p.active
+	p.active = true
+	p.mu.Unlock()
+
+	if !schedule {
+		return nil
+	}
+
+	if err := w.pool.Submit(ctx, idx); err != nil {
+		// Неактивная партиция пуста, поэтому наш item первый. Item, добавленные за ним,
+		// уже приняты и достанутся Shutdown
+		p.mu.Lock()
+		p.items[0] = std.Zero[T]()
+		p.items = p.items[1:]
+		p.active = false
+		p.mu.Unlock()
+
+		return err
+	}
+
+	return nil
+}
+
+// runPartition обрабатывает один item партиции и, если там есть ещё, ставит её в конец очереди,
+// чтобы длинная партиция не задерживала остальные.
+func (w *partitionedWorkerpool[T]) runPartition(ctx context.Context, idx int) error {
+	p := w.partitions[idx]
+
+	p.mu.Lock()
+	item := p.items[0]
+	p.items[0] = std.Zero[T]()
+	p.items = p.items[1:]
+	p.running = true
+	p.mu.Unlock()
+
+	defer func() {
+		p.mu.Lock()
+		p.running = false
+		p.active = len(p.items) > 0
+		reschedule := p.active
+		p.mu.Unlock()
+
+		// Очередь вмещает все партиции, поэтому постановка не удаётся, только если пул уже не принимает item.
+		// Тогда оставшиеся item вернёт Shutdown
+		if reschedule {
+			if _, err := w.pool.TrySubmit(idx); err != nil {
+				p.mu.Lock()
+				p.active = false
+				p.mu.Unlock()
+			}
+		}
+
+		xchan.TrySendNonBlocking(w.idle, struct{}{})
+	}()
+
+	panicErr, attempts := runWithRetries(ctx, w.retries, func() { w.f(ctx, item) })
+	if panicErr != nil {
+		w.panics.report(Panic[T]{Item: item, Panic: panicErr.Payload, Stack: panicErr.Stack(), Attempts: attempts})
+		// Воркер не заменяется: паника уже перехвачена, но в статистике item учитывается как паника
+		return *panicErr
+	}
+
+	return nil
+}
+
+// Panics возвращает поток паник или nil, если он не включён опцией WithPanicStream.
+func (w *partitionedWorkerpool[T]) Panics() <-chan Panic[T] {
+	return w.panics.stream
+}
+
+// Backlog возвращает число ожидающих item в каждой партиции, включая обрабатываемый.
+func (w *partitionedWorkerpool[T]) Backlog() []int {
+	result := make([]int, len(w.partitions))
+	for i, p := range w.partitions {
+		p.mu.Lock()
+		result[i] = len(p.items)
+		if p.running {
+			result[i]++
+		}
+		p.mu.Unlock()
+	}
+
+	return result
+}
+
+func (w *partitionedWorkerpool[T]) SetWorkersCount(count int) {
+	w.pool.SetWorkersCount(count)
+}
+
+func (w *partitionedWorkerpool[T]) WorkersCount() int {
+	return w.pool.WorkersCount()
+}
+
+// GetStat возвращает снимок статистики пула. QueueDepth - число item, ожидающих в партициях.
+func (w *partitionedWorkerpool[T]) GetStat() Stat {
+	st := w.pool.GetStat()
+	st.Submitted = w.metrics.submitted.Load()
+	st.Rejected = w.metrics.rejected.Load()
+
+	st.QueueDepth = 0
+	for _, p := range w.partitions {
+		p.mu.Lock()
+		st.QueueDepth += len(p.items)
+		p.mu.Unlock()
+	}
+
+	return st
+}
+
+func (w *partitionedWorkerpool[T]) ViewStat(f func(*Stat)) {
+	st := w.GetStat()
+	f(&st)
+}
+
+func (w *partitionedWorkerpool[T]) SetRateLimit(limit RateLimit) {
+	w.pool.SetRateLimit(limit)
+}
+
+func (w *partitionedWorkerpool[T]) SetKeyRateLimit(key string, limit RateLimit) {
+	w.pool.SetKeyRateLimit(key, limit)
+}
@@ -0,0 +1,96 @@
+package workerpool_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SlamJam/go-libs/workerpool"
+	"github.com/stretchr/testify/assert"
+)
+
+type event struct {
+	User string
+	Seq  int
+}
+
+func TestPartitionedWorkerpoolOrdering(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	const users, perUser = 8, 50
+
+	var mu sync.Mutex
+	seen := map[string][]int{}
+	var wg sync.WaitGroup
+
+	w := workerpool.NewPartitioned(func(ctx context.Context, e event) {
+		defer wg.Done()
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		seen[e.User] = append(seen[e.User], e.Seq)
+	}, func(e event) string { return e.User }, 4, 2)
+
+	w.Start(ctx)
+
+	for seq := range perUser {
+		// Меняем число воркеров на ходу, порядок внутри ключа сохраняется
+		if seq == perUser/2 {
+			w.SetWorkersCount(4)
+		}
+
+		for u := range users {
+			wg.Add(1)
+			assert.NoError(t, w.Submit(ctx, event{User: fmt.Sprint("user", u), Seq: seq}))
+		}
+	}
+
+	wg.Wait()
+
+	for u := range users {
+		got := seen[fmt.Sprint("user", u)]
+		if assert.Len(t, got, perUser) {
+			for i, seq := range got {
+				assert.Equal(t, i, seq)
+			}
+		}
+	}
+
+	assert.Equal(t, make([]int, 4), w.Backlog())
+}
+
+func TestPartitionedWorkerpoolBacklog(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	release := make(chan struct{})
+
+	w := workerpool.NewPartitioned(func(ctx context.Context, e event) {
+		<-release
+	}, func(e event) string { return e.User }, 2, 1)
+
+	w.Start(ctx)
+
+	for seq := range 3 {
+		assert.NoError(t, w.Submit(ctx, event{User: "a", Seq: seq}))
+	}
+
+	idx := w.PartitionOf("a")
+	assert.Eventually(t, func() bool {
+		return w.Backlog()[idx] == 3
+	}, time.Second, time.Millisecond)
+
+	close(release)
+
+	assert.Eventually(t, func() bool {
+		return w.Backlog()[idx] == 0
+	}, time.Second, time.Millisecond)
+}
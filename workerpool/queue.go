@@ -0,0 +1,196 @@
+package workerpool
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrQueueFull = errors.New("workerpool queue is full")
+	ErrDropped   = errors.New("item was dropped from workerpool queue")
+)
+
+// QueuePolicy определяет, что делать с новым item, если очередь заполнена.
+type QueuePolicy int
+
+const (
+	// QueueBlock ждёт места в очереди.
+	QueueBlock QueuePolicy = iota
+	// QueueReject возвращает ErrQueueFull.
+	QueueReject
+	// QueueDropOldest вытесняет самый старый item из очереди.
+	QueueDropOldest
+	// QueueDropNewest отбрасывает новый item.
+	QueueDropNewest
+	// QueueCallerRuns выполняет item в горутине отправителя так же, как воркер: с ctx пула,
+	// таймаутами и обработкой паник. До старта пула возвращает ErrQueueFull.
+	QueueCallerRuns
+)
+
+// SubmitResult сообщает, что произошло с отправленным item.
+type SubmitResult int
+
+const (
+	Enqueued SubmitResult = iota
+	Rejected
+	DroppedOldest
+	DroppedNewest
+	RanByCaller
+)
+
+// WithQueue ставит перед воркерами очередь ёмкостью capacity с политикой переполнения policy.
+// По умолчанию очереди нет: item передаётся воркеру напрямую, отправитель ждёт.
+// Несовместима с WithFairQueue: там ёмкость задаёт FairConfig.ClassCap.
+func WithQueue(capacity int, policy QueuePolicy) Opt {
+	return func(o *poolOptions) {
+		o.queueCap = capacity
+		o.queuePolicy = policy
+	}
+}
+
+// Submit отправляет item в пул согласно политике очереди.
+func (w *workerpool[T]) Submit(ctx context.Context, item T) error {
+	_, err := w.SubmitContext(ctx, item)
+	return err
+}
+
+// SubmitContext отправляет item в пул согласно политике очереди и сообщает, какая политика сработала.
+// При политике QueueBlock ждёт места в очереди, пока не отменён ctx.
+func (w *workerpool[T]) SubmitContext(ctx context.Context, item T) (SubmitResult, error) {
+	return w.submit(ctx, item, true)
+}
+
+// TrySubmit как SubmitContext, но никогда не ждёт: при QueueBlock и заполненной очереди возвращает ErrQueueFull.
+func (w *workerpool[T]) TrySubmit(item T) (SubmitResult, error) {
+	return w.submit(context.Background(), item, false)
+}
+
+func (w *workerpool[T]) submit(ctx context.Context, item T, wait bool) (SubmitResult, error) {
+	res, err := w.submitItem(ctx, item, wait)
+	w.metrics.onSubmit(res, err)
+
+	return res, err
+}
+
+func (w *workerpool[T]) submitItem(ctx context.Context, item T, wait bool) (SubmitResult, error) {
+	w.submitMu.RLock()
+	defer w.submitMu.RUnlock()
+
+	if err := w.acceptErr(); err != nil {
+		return Rejected, err
+	}
+
+	if w.fair != nil {
+		if err := w.fair.push(ctx, w.fair.lowest(), "", item, wait, w.rejecting, w.submitErr); err != nil {
+			return Rejected, err
+		}
+
+		return Enqueued, nil
+	}
+
+	if w.opts.queuePolicy == QueueDropOldest {
+		return w.enqueueDroppingOldest(item), nil
+	}
+
+	if w.tryEnqueue(item) {
+		return Enqueued, nil
+	}
+
+	switch w.opts.queuePolicy {
+	case QueueReject:
+		return Rejected, ErrQueueFull
+	case QueueDropNewest:
+		return DroppedNewest, nil
+	case QueueCallerRuns:
+		if !w.runByCaller(item) {
+			return Rejected, ErrQueueFull
+		}
+
+		return RanByCaller, nil
+	}
+
+	if !wait {
+		return Rejected, ErrQueueFull
+	}
+
+	return w.enqueueWait(ctx, item)
+}
+
+func (w *workerpool[T]) tryEnqueue(item T) bool {
+	select {
+	case w.Input <- item:
+		return true
+	default:
+		return false
+	}
+}
+
+func (w *workerpool[T]) enqueueWait(ctx context.Context, item T) (SubmitResult, error) {
+	w.submitting.Add(1)
+	defer w.submitting.Add(-1)
+
+	startedAt := time.Now()
+
+	select {
+	case w.Input <- item:
+		w.observeSubmitWait(time.Since(startedAt))
+		return Enqueued, nil
+	case <-w.rejecting:
+		return Rejected, w.submitErr()
+	case <-ctx.Done():
+		return Rejected, ctx.Err()
+	}
+}
+
+// enqueueDroppingOldest ставит item, вытесняя самые старые. Отправители сериализуются, поэтому, пока
+// мы держим блокировку, очередь может только опустеть: если вытеснять нечего, следующая постановка удастся.
+func (w *workerpool[T]) enqueueDroppingOldest(item T) SubmitResult {
+	w.dropMu.Lock()
+	defer w.dropMu.Unlock()
+
+	result := Enqueued
+
+	for !w.tryEnqueue(item) {
+		select {
+		case old := <-w.Input:
+			result = DroppedOldest
+			w.metrics.onDrop()
+			if w.onDrop != nil {
+				w.onDrop(old)
+			}
+		default:
+		}
+	}
+
+	return result
+}
+
+// runByCaller выполняет item в горутине отправителя. До старта пула ctx воркеров ещё нет,
+// и item не выполняется.
+func (w *workerpool[T]) runByCaller(item T) bool {
+	var started bool
+
+	w.condr.RDo(func() {
+		started = w.ctx != nil
+	})
+
+	if !started {
+		return false
+	}
+
+	w.process(item)
+
+	return true
+}
+
+// QueueLen возвращает число item, ожидающих воркера.
+func (w *workerpool[T]) QueueLen() int {
+	result := len(w.Input)
+	if w.fair != nil {
+		result += w.fair.len()
+	}
+
+	return result
+}
@@ -0,0 +1,218 @@
+package workerpool_test
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/SlamJam/go-libs/co"
+	"github.com/SlamJam/go-libs/workerpool"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkerpoolQueuePolicies(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		policy    workerpool.QueuePolicy
+		result    workerpool.SubmitResult
+		err       error
+		processed []int
+	}{
+		{workerpool.QueueReject, workerpool.Rejected, workerpool.ErrQueueFull, []int{0, 1, 2}},
+		{workerpool.QueueDropNewest, workerpool.DroppedNewest, nil, []int{0, 1, 2}},
+		{workerpool.QueueDropOldest, workerpool.DroppedOldest, nil, []int{0, 2, 3}},
+		{workerpool.QueueCallerRuns, workerpool.RanByCaller, nil, []int{3, 0, 1, 2}},
+	}
+
+	for _, c := range cases {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		b := newBlockedPool(t, ctx, c.policy)
+
+		res, err := b.pool.TrySubmit(3)
+		assert.Equal(t, c.result, res, c.policy)
+		assert.ErrorIs(t, err, c.err)
+
+		close(b.release)
+
+		var got []int
+		for range c.processed {
+			got = append(got, <-b.processed)
+		}
+		assert.Equal(t, c.processed, got, c.policy)
+
+		cancel()
+	}
+}
+
+func TestWorkerpoolQueueBlock(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	b := newBlockedPool(t, ctx, workerpool.QueueBlock)
+
+	res, err := b.pool.TrySubmit(3)
+	assert.Equal(t, workerpool.Rejected, res)
+	assert.ErrorIs(t, err, workerpool.ErrQueueFull)
+
+	submitCtx, submitCancel := context.WithCancel(ctx)
+	submitCancel()
+
+	_, err = b.pool.SubmitContext(submitCtx, 3)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	close(b.release)
+
+	res, err = b.pool.SubmitContext(ctx, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, workerpool.Enqueued, res)
+}
+
+func TestWorkerpoolWithResultDropOldest(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	w := workerpool.NewWithResult(func(ctx context.Context, i int) (int, error) {
+		if i == 0 {
+			close(started)
+			<-release
+		}
+
+		return i, nil
+	}, 1, workerpool.WithQueue(1, workerpool.QueueDropOldest))
+
+	w.Start(ctx)
+
+	p0 := w.Submit(ctx, 0)
+	<-started
+
+	p1 := w.Submit(ctx, 1)
+	p2, res := w.TrySubmit(2)
+	assert.Equal(t, workerpool.DroppedOldest, res)
+
+	close(release)
+
+	_, err := p1.Poll(ctx)
+	assert.ErrorIs(t, err, workerpool.ErrDropped)
+
+	rest := co.MultiPromise[int]{p0, p2}
+	for i, p := range rest {
+		result, err := p.Poll(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, i*2, result)
+	}
+}
+
+func TestWorkerpoolDropOldestConcurrent(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var processed atomic.Int64
+
+	pool := workerpool.New(func(ctx context.Context, i int) {
+		processed.Add(1)
+	}, 2, workerpool.WithQueue(2, workerpool.QueueDropOldest))
+
+	pool.Start(ctx)
+
+	const n = 1000
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := range n {
+				_, err := pool.TrySubmit(i)
+				assert.NoError(t, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	dropped, err := pool.Shutdown(ctx, true)
+	assert.NoError(t, err)
+	assert.Empty(t, dropped)
+
+	// Каждый item либо обработан, либо вытеснен
+	assert.Equal(t, uint64(8*n), uint64(processed.Load())+pool.GetStat().Dropped)
+}
+
+func TestWorkerpoolFairQueueRejectsQueue(t *testing.T) {
+	t.Parallel()
+
+	f := func(ctx context.Context, i int) {}
+	fair := workerpool.WithFairQueue(workerpool.FairConfig{Classes: 2})
+
+	assert.Panics(t, func() { workerpool.New(f, 1, fair, workerpool.WithQueue(4, workerpool.QueueDropOldest)) })
+	assert.Panics(t, func() { workerpool.New(f, 1, fair, workerpool.WithQueue(4, workerpool.QueueBlock)) })
+	assert.Panics(t, func() { workerpool.NewPartitioned(f, strconv.Itoa, 4, 1, fair) })
+}
+
+func TestWorkerpoolCallerRunsLikeWorker(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+
+	pool := workerpool.New(func(ctx context.Context, i int) {
+		switch i {
+		case 0:
+			close(started)
+			<-release
+		case 2:
+			panic("boom")
+		case 3:
+			<-ctx.Done()
+		}
+	}, 1,
+		workerpool.WithQueue(1, workerpool.QueueCallerRuns),
+		workerpool.WithPanicStream(1),
+		workerpool.WithJobTimeout(20*time.Millisecond),
+	)
+
+	// До старта выполнять item как воркер нельзя
+	_, err := pool.TrySubmit(0)
+	assert.NoError(t, err)
+	res, err := pool.TrySubmit(1)
+	assert.Equal(t, workerpool.Rejected, res)
+	assert.ErrorIs(t, err, workerpool.ErrQueueFull)
+
+	pool.Start(ctx)
+	<-started
+
+	_, err = pool.TrySubmit(1)
+	assert.NoError(t, err)
+
+	// Паника item, выполненного отправителем, не доходит до него, а попадает в поток паник
+	assert.NotPanics(t, func() {
+		res, err = pool.TrySubmit(2)
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, workerpool.RanByCaller, res)
+	assert.Equal(t, 2, (<-pool.Panics).Item)
+
+	// Таймаут item действует и в горутине отправителя
+	res, err = pool.TrySubmit(3)
+	assert.NoError(t, err)
+	assert.Equal(t, workerpool.RanByCaller, res)
+	assert.Equal(t, uint64(1), pool.GetStat().TimedOut)
+}
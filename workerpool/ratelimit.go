@@ -0,0 +1,309 @@
+package workerpool
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/SlamJam/go-libs/options"
+	"github.com/SlamJam/go-libs/xchan"
+)
+
+// RateLimit - ограничение token bucket.
+type RateLimit struct {
+	// Сколько item в секунду. 0 - без ограничения
+	Rate float64
+	// Сколько item можно выполнить подряд после простоя. По умолчанию 1
+	Burst int
+}
+
+func (l RateLimit) burst() float64 {
+	return float64(max(l.Burst, 1))
+}
+
+// WithRateLimit ограничивает темп, с которым item передаются воркерам.
+// Item ждут своей очереди перед воркерами в порядке поступления, а не занимают воркеров ожиданием.
+// Ограничение можно менять через SetRateLimit, только если оно включено при создании пула.
+// Несовместима с QueueCallerRuns: отправитель выполнил бы item в обход ограничения.
+func WithRateLimit(limit RateLimit) Opt {
+	return func(o *poolOptions) {
+		o.rateLimit = &limit
+	}
+}
+
+// WithKeyRateLimit дополнительно ограничивает темп для каждого ключа key(item) отдельно.
+// Тип T должен совпадать с типом item пула. Порядок item сохраняется, поэтому item,
+// ждущий свой ключ, задерживает следующие за ним; с WithFairQueue ключи чередуются.
+func WithKeyRateLimit[T any](key func(T) string, limit RateLimit) Opt {
+	return func(o *poolOptions) {
+		o.rateKey = key
+		o.keyRateLimit = limit
+	}
+}
+
+// withRateKey подменяет функцию ключа, когда пул обёрнут и тип его item отличается от пользовательского.
+func withRateKey[T any](key func(T) string) Opt {
+	return func(o *poolOptions) {
+		if o.rateKey != nil {
+			o.rateKey = key
+		}
+	}
+}
+
+// withJobRateKey переводит функцию ключа пользователя на item пула с результатом.
+func withJobRateKey[T, R any](opts []Opt) Opt {
+	key := options.Typed[func(T) string](options.Create(opts...).rateKey, "workerpool: WithKeyRateLimit")
+	if key == nil {
+		return func(*poolOptions) {}
+	}
+
+	return withRateKey(func(j job[T, R]) string {
+		return key(j.item)
+	})
+}
+
+type tokenBucket struct {
+	limit  RateLimit
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(limit RateLimit) *tokenBucket {
+	return &tokenBucket{limit: limit, tokens: limit.burst()}
+}
+
+func (b *tokenBucket) advance(now time.Time) {
+	if !b.last.IsZero() && b.limit.Rate > 0 {
+		b.tokens = math.Min(b.limit.burst(), b.tokens+now.Sub(b.last).Seconds()*b.limit.Rate)
+	}
+
+	b.last = now
+}
+
+// delay возвращает, через сколько появится токен.
+func (b *tokenBucket) delay(now time.Time) time.Duration {
+	if b.limit.Rate <= 0 {
+		return 0
+	}
+
+	b.advance(now)
+	if b.tokens >= 1 {
+		return 0
+	}
+
+	return time.Duration(math.Ceil((1 - b.tokens) / b.limit.Rate * float64(time.Second)))
+}
+
+func (b *tokenBucket) take() {
+	if b.limit.Rate > 0 {
+		b.tokens--
+	}
+}
+
+func (b *tokenBucket) setLimit(now time.Time, limit RateLimit) {
+	b.advance(now)
+	b.limit = limit
+	b.tokens = math.Min(b.tokens, limit.burst())
+}
+
+func (b *tokenBucket) isFull(now time.Time) bool {
+	b.advance(now)
+	return b.tokens >= b.limit.burst()
+}
+
+type rateLimiter[T any] struct {
+	mu     sync.Mutex
+	global *tokenBucket
+
+	keyOf      func(T) string
+	keyDefault RateLimit
+	keyLimits  map[string]RateLimit
+	keys       map[string]*tokenBucket
+	nextSweep  int
+
+	// Сигнал о смене ограничений, чтобы ждущий пересчитал задержку
+	changed chan struct{}
+}
+
+func newRateLimiter[T any](o poolOptions) *rateLimiter[T] {
+	if o.rateLimit == nil && o.rateKey == nil {
+		return nil
+	}
+
+	l := &rateLimiter[T]{
+		global:    newTokenBucket(RateLimit{}),
+		keyLimits: map[string]RateLimit{},
+		keys:      map[string]*tokenBucket{},
+		nextSweep: 64,
+		changed:   make(chan struct{}, 1),
+	}
+
+	if o.rateLimit != nil {
+		l.global = newTokenBucket(*o.rateLimit)
+	}
+
+	if o.rateKey != nil {
+		l.keyOf = options.Typed[func(T) string](o.rateKey, "workerpool: WithKeyRateLimit")
+		l.keyDefault = o.keyRateLimit
+	}
+
+	return l
+}
+
+func (l *rateLimiter[T]) keyBucket(now time.Time, key string) *tokenBucket {
+	b, ok := l.keys[key]
+	if ok {
+		return b
+	}
+
+	// Полные корзины ничем не отличаются от новых, их можно забыть
+	if len(l.keys) >= l.nextSweep {
+		for k, kb := range l.keys {
+			if kb.isFull(now) {
+				delete(l.keys, k)
+			}
+		}
+
+		l.nextSweep = max(2*len(l.keys), 64)
+	}
+
+	limit, ok := l.keyLimits[key]
+	if !ok {
+		limit = l.keyDefault
+	}
+
+	b = newTokenBucket(limit)
+	l.keys[key] = b
+
+	return b
+}
+
+// tryTake забирает токены для item или возвращает, сколько ещё ждать.
+func (l *rateLimiter[T]) tryTake(item T) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+
+	var kb *tokenBucket
+	if l.keyOf != nil {
+		kb = l.keyBucket(now, l.keyOf(item))
+	}
+
+	wait := l.global.delay(now)
+	if kb != nil {
+		wait = max(wait, kb.delay(now))
+	}
+
+	if wait > 0 {
+		return wait
+	}
+
+	l.global.take()
+	if kb != nil {
+		kb.take()
+	}
+
+	return 0
+}
+
+// wait ждёт токены для item. Возвращает false, если ctx отменён раньше.
+func (l *rateLimiter[T]) wait(ctx context.Context, item T) bool {
+	for {
+		d := l.tryTake(item)
+		if d == 0 {
+			return true
+		}
+
+		timer := time.NewTimer(d)
+
+		select {
+		case <-timer.C:
+		case <-l.changed:
+			timer.Stop()
+		case <-ctx.Done():
+			timer.Stop()
+			return false
+		}
+	}
+}
+
+func (l *rateLimiter[T]) setGlobal(limit RateLimit) {
+	l.mu.Lock()
+	l.global.setLimit(time.Now(), limit)
+	l.mu.Unlock()
+
+	xchan.TrySendNonBlocking(l.changed, struct{}{})
+}
+
+func (l *rateLimiter[T]) setKey(key string, limit RateLimit) {
+	l.mu.Lock()
+	l.keyLimits[key] = limit
+	if b, ok := l.keys[key]; ok {
+		b.setLimit(time.Now(), limit)
+	}
+	l.mu.Unlock()
+
+	xchan.TrySendNonBlocking(l.changed, struct{}{})
+}
+
+// limit передаёт item из Input воркерам с темпом, заданным ограничителем.
+// После Shutdown завершается, как только в Input не останется item.
+func (w *workerpool[T]) limit(ctx context.Context) {
+	defer close(w.limited)
+
+	// С очередью с приоритетами item продолжают поступать, пока диспетчер не разберёт её
+	stop := w.closing
+	if w.dispatched != nil {
+		stop = w.dispatched
+	}
+
+	for {
+		var item T
+		var ok bool
+
+		select {
+		case item, ok = <-w.Input:
+		case <-stop:
+			select {
+			case item, ok = <-w.Input:
+			default:
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+
+		if !ok {
+			close(w.ready)
+			return
+		}
+
+		if !w.limiter.wait(ctx, item) {
+			w.strand(item)
+			return
+		}
+
+		select {
+		case w.ready <- item:
+		case <-ctx.Done():
+			w.strand(item)
+			return
+		}
+	}
+}
+
+// SetRateLimit меняет общее ограничение темпа. Требует WithRateLimit или WithKeyRateLimit.
+func (w *workerpool[T]) SetRateLimit(limit RateLimit) {
+	if w.limiter != nil {
+		w.limiter.setGlobal(limit)
+	}
+}
+
+// SetKeyRateLimit меняет ограничение темпа для одного ключа. Требует WithKeyRateLimit.
+func (w *workerpool[T]) SetKeyRateLimit(key string, limit RateLimit) {
+	if w.limiter != nil {
+		w.limiter.setKey(key, limit)
+	}
+}
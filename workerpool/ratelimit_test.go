@@ -0,0 +1,183 @@
+package workerpool_test
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SlamJam/go-libs/co"
+	"github.com/SlamJam/go-libs/workerpool"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkerpoolRateLimit(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(6)
+
+	w := workerpool.New(func(ctx context.Context, i int) {
+		wg.Done()
+	}, 4, workerpool.WithQueue(8, workerpool.QueueBlock), workerpool.WithRateLimit(workerpool.RateLimit{Rate: 100, Burst: 2}))
+
+	w.Start(ctx)
+
+	startedAt := time.Now()
+	for i := range 6 {
+		assert.NoError(t, w.Submit(ctx, i))
+	}
+
+	wg.Wait()
+
+	// 2 сразу, ещё 4 по 10ms
+	assert.GreaterOrEqual(t, time.Since(startedAt), 35*time.Millisecond)
+}
+
+func TestWorkerpoolKeyRateLimit(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	type item struct {
+		key string
+		n   int
+	}
+
+	var mu sync.Mutex
+	var order []int
+
+	w := workerpool.New(func(ctx context.Context, it item) {
+		mu.Lock()
+		order = append(order, it.n)
+		mu.Unlock()
+	}, 1,
+		workerpool.WithQueue(8, workerpool.QueueBlock),
+		workerpool.WithKeyRateLimit(func(it item) string { return it.key }, workerpool.RateLimit{Rate: 0.01}),
+	)
+
+	w.SetKeyRateLimit("fast", workerpool.RateLimit{})
+
+	w.Start(ctx)
+
+	assert.NoError(t, w.Submit(ctx, item{"slow", 0}))
+	assert.NoError(t, w.Submit(ctx, item{"fast", 1}))
+	assert.NoError(t, w.Submit(ctx, item{"fast", 2}))
+	assert.NoError(t, w.Submit(ctx, item{"slow", 3}))
+	assert.NoError(t, w.Submit(ctx, item{"fast", 4}))
+
+	// Второй item "slow" ждёт токен и задерживает следующие за ним
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(order) == 3
+	}, time.Second, time.Millisecond)
+
+	time.Sleep(20 * time.Millisecond)
+
+	mu.Lock()
+	assert.Equal(t, []int{0, 1, 2}, order)
+	mu.Unlock()
+
+	w.SetKeyRateLimit("slow", workerpool.RateLimit{Rate: 1000})
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(order) == 5
+	}, time.Second, time.Millisecond)
+
+	mu.Lock()
+	assert.Equal(t, []int{0, 1, 2, 3, 4}, order)
+	mu.Unlock()
+}
+
+func TestWorkerpoolRateLimitShutdown(t *testing.T) {
+	t.Parallel()
+
+	w := workerpool.New(func(ctx context.Context, i int) {}, 1,
+		workerpool.WithQueue(4, workerpool.QueueBlock),
+		workerpool.WithRateLimit(workerpool.RateLimit{Rate: 0.01}),
+	)
+
+	w.Start(context.Background())
+	assert.NoError(t, w.WaitUntilStarted(context.Background()))
+
+	for i := range 3 {
+		assert.NoError(t, w.Submit(context.Background(), i))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+
+	unprocessed, err := w.Shutdown(ctx, true)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ElementsMatch(t, []int{1, 2}, unprocessed)
+}
+
+func TestWorkerpoolKeyRateLimitTypeMismatch(t *testing.T) {
+	t.Parallel()
+
+	assert.Panics(t, func() {
+		workerpool.NewWithResult(func(ctx context.Context, i int) (int, error) {
+			return i, nil
+		}, 1, workerpool.WithKeyRateLimit(func(s string) string { return s }, workerpool.RateLimit{Rate: 1}))
+	})
+
+	assert.Panics(t, func() {
+		workerpool.NewPartitioned(func(ctx context.Context, i int) {}, strconv.Itoa, 4, 1,
+			workerpool.WithKeyRateLimit(func(s string) string { return s }, workerpool.RateLimit{Rate: 1}))
+	})
+}
+
+func TestWorkerpoolRateLimitInterrupt(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	w := workerpool.NewWithResult(func(ctx context.Context, i int) (int, error) {
+		return i, nil
+	}, 1, workerpool.WithQueue(4, workerpool.QueueBlock), workerpool.WithRateLimit(workerpool.RateLimit{Rate: 0.01}))
+
+	w.Start(ctx)
+
+	var promises []co.Promise[int]
+	for i := range 3 {
+		promises = append(promises, w.Submit(ctx, i))
+	}
+
+	_, err := promises[0].Poll(ctx)
+	assert.NoError(t, err)
+
+	cancel()
+
+	// Item, который ждал токен в ограничителе, и item в очереди отклоняются, а не теряются
+	waitCtx, waitCancel := context.WithTimeout(context.Background(), time.Second)
+	defer waitCancel()
+
+	for _, p := range promises[1:] {
+		_, err := p.Poll(waitCtx)
+		assert.ErrorIs(t, err, workerpool.ErrHalted)
+	}
+}
+
+func TestWorkerpoolRateLimitRejectsCallerRuns(t *testing.T) {
+	t.Parallel()
+
+	f := func(ctx context.Context, i int) {}
+	callerRuns := workerpool.WithQueue(4, workerpool.QueueCallerRuns)
+
+	assert.Panics(t, func() {
+		workerpool.New(f, 1, callerRuns, workerpool.WithRateLimit(workerpool.RateLimit{Rate: 1}))
+	})
+
+	assert.Panics(t, func() {
+		workerpool.New(f, 1, callerRuns, workerpool.WithKeyRateLimit(strconv.Itoa, workerpool.RateLimit{Rate: 1}))
+	})
+}
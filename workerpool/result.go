@@ -0,0 +1,193 @@
+package workerpool
+
+import (
+	"context"
+	"sync/atomic"
+
+	std "github.com/SlamJam/go-libs"
+	"github.com/SlamJam/go-libs/actors"
+	"github.com/SlamJam/go-libs/co"
+	"github.com/SlamJam/go-libs/options"
+	"github.com/SlamJam/go-libs/xgo"
+)
+
+type job[T, R any] struct {
+	item    T
+	resolve func(R, error)
+	// Заданы только у item, отправленных через SubmitJob
+	ctx    context.Context
+	cancel context.CancelFunc
+	state  *atomic.Int32
+}
+
+func (j job[T, R]) finish(result R, err error) {
+	j.resolve(result, err)
+
+	if j.cancel != nil {
+		j.cancel()
+	}
+}
+
+type resultWorkerpool[T, R any] struct {
+	actors.Actor
+
+	pool   *workerpool[job[T, R]]
+	errors chan Error[T]
+	panics panicReporter[T]
+}
+
+// NewWithResult создаёт пул, в котором результат каждого item возвращается через Promise.
+// С опцией WithErrors ошибки дополнительно дублируются в Errors().
+func NewWithResult[T, R any](f func(context.Context, T) (R, error), maxworkers int, opts ...Opt) *resultWorkerpool[T, R] {
+	w := &resultWorkerpool[T, R]{}
+
+	w.pool = newWorkerpool(func(ctx context.Context, j job[T, R]) error {
+		// Отменённый через Job.Cancel item уже отклонён, выполнять его незачем
+		if j.state != nil && !j.state.CompareAndSwap(jobQueued, jobRunning) {
+			j.finish(std.Zero[R](), context.Canceled)
+			return context.Canceled
+		}
+
+		result, err := f(ctx, j.item)
+		if err != nil && isTimedOut(ctx) {
+			err = &TimeoutError{Cause: err}
+		}
+		j.finish(result, err)
+
+		// ctx item у отменённого или просроченного item уже завершён, поэтому ждём читателя до остановки пула
+		if err != nil && w.errors != nil {
+			select {
+			case w.errors <- Error[T]{Item: j.item, Error: err}:
+			case <-w.pool.ctx.Done():
+			}
+		}
+
+		return err
+	}, maxworkers, append(opts[:len(opts):len(opts)], withoutPanicReporting(), withJobRateKey[T, R](opts))...)
+
+	w.panics = newPanicReporter[T](options.Create(opts...))
+	w.pool.onPanic = func(p Panic[job[T, R]]) {
+		// Паника отклоняет Promise, а не оставляет вызывающего ждать навсегда
+		p.Item.finish(std.Zero[R](), xgo.NewPanicError(p.Panic, p.Stack))
+		w.panics.report(Panic[T]{Item: p.Item.item, Panic: p.Panic, Stack: p.Stack, Attempts: p.Attempts})
+	}
+
+	w.pool.onDrop = func(j job[T, R]) {
+		j.finish(std.Zero[R](), ErrDropped)
+	}
+
+	w.pool.onReject = func(j job[T, R], err error) {
+		j.finish(std.Zero[R](), err)
+	}
+
+	w.pool.onAbandon = func(j job[T, R], err error) {
+		j.finish(std.Zero[R](), err)
+	}
+
+	w.pool.itemContext = func(j job[T, R]) context.Context {
+		return j.ctx
+	}
+
+	if size := w.pool.opts.errorsCap; size > 0 {
+		w.errors = make(chan Error[T], size)
+	}
+
+	w.Actor = w.pool.Actor
+
+	return w
+}
+
+// Submit ставит item в очередь и возвращает Promise с результатом его обработки.
+// Если item не удалось передать в пул, Promise сразу отклонён с причиной.
+func (w *resultWorkerpool[T, R]) Submit(ctx context.Context, item T) co.Promise[R] {
+	p, _ := w.SubmitContext(ctx, item)
+	return p
+}
+
+// SubmitContext как Submit, но дополнительно сообщает, какая политика очереди сработала.
+// Вытесненный или отброшенный item отклоняет свой Promise с ErrDropped.
+func (w *resultWorkerpool[T, R]) SubmitContext(ctx context.Context, item T) (co.Promise[R], SubmitResult) {
+	p, resolve := co.NewPending[R]()
+	res, err := w.pool.SubmitContext(ctx, job[T, R]{item: item, resolve: resolve})
+
+	return w.promiseFor(p, res, err), res
+}
+
+// TrySubmit как SubmitContext, но никогда не ждёт места в очереди.
+func (w *resultWorkerpool[T, R]) TrySubmit(item T) (co.Promise[R], SubmitResult) {
+	p, resolve := co.NewPending[R]()
+	res, err := w.pool.TrySubmit(job[T, R]{item: item, resolve: resolve})
+
+	return w.promiseFor(p, res, err), res
+}
+
+// SubmitFair ставит item в класс приоритета class от имени ключа key (см. WithFairQueue).
+func (w *resultWorkerpool[T, R]) SubmitFair(ctx context.Context, class int, key string, item T) co.Promise[R] {
+	p, resolve := co.NewPending[R]()
+	if err := w.pool.SubmitFair(ctx, class, key, job[T, R]{item: item, resolve: resolve}); err != nil {
+		return co.NewRejected[R](err)
+	}
+
+	return p
+}
+
+func (w *resultWorkerpool[T, R]) promiseFor(p co.Promise[R], res SubmitResult, err error) co.Promise[R] {
+	switch {
+	case err != nil:
+		return co.NewRejected[R](err)
+	case res == DroppedNewest:
+		return co.NewRejected[R](ErrDropped)
+	default:
+		return p
+	}
+}
+
+// Errors возвращает поток ошибок или nil, если он не включён опцией WithErrors.
+func (w *resultWorkerpool[T, R]) Errors() <-chan Error[T] {
+	return w.errors
+}
+
+// Panics возвращает поток паник или nil, если он не включён опцией WithPanicStream.
+func (w *resultWorkerpool[T, R]) Panics() <-chan Panic[T] {
+	return w.panics.stream
+}
+
+func (w *resultWorkerpool[T, R]) SetWorkersCount(count int) {
+	w.pool.SetWorkersCount(count)
+}
+
+func (w *resultWorkerpool[T, R]) WorkersCount() int {
+	return w.pool.WorkersCount()
+}
+
+func (w *resultWorkerpool[T, R]) AutoscaleStat() AutoscaleStat {
+	return w.pool.AutoscaleStat()
+}
+
+func (w *resultWorkerpool[T, R]) QueueLen() int {
+	return w.pool.QueueLen()
+}
+
+func (w *resultWorkerpool[T, R]) SetKeyWeight(key string, weight int) {
+	w.pool.SetKeyWeight(key, weight)
+}
+
+func (w *resultWorkerpool[T, R]) ClassStats() []ClassStat {
+	return w.pool.ClassStats()
+}
+
+func (w *resultWorkerpool[T, R]) GetStat() Stat {
+	return w.pool.GetStat()
+}
+
+func (w *resultWorkerpool[T, R]) ViewStat(f func(*Stat)) {
+	w.pool.ViewStat(f)
+}
+
+func (w *resultWorkerpool[T, R]) SetRateLimit(limit RateLimit) {
+	w.pool.SetRateLimit(limit)
+}
+
+func (w *resultWorkerpool[T, R]) SetKeyRateLimit(key string, limit RateLimit) {
+	w.pool.SetKeyRateLimit(key, limit)
+}
@@ -0,0 +1,237 @@
+package workerpool
+
+import (
+	"context"
+
+	std "github.com/SlamJam/go-libs"
+	"github.com/SlamJam/go-libs/xsync"
+	"github.com/pkg/errors"
+)
+
+var ErrShutdown = errors.New("workerpool is shut down")
+
+func (w *workerpool[T]) reject() {
+	w.rejectOnce.Do(func() {
+		close(w.rejecting)
+	})
+}
+
+func (w *workerpool[T]) isClosing() bool {
+	select {
+	case <-w.closing:
+		return true
+	default:
+		return false
+	}
+}
+
+// submitErr возвращает причину, по которой пул больше не принимает item.
+func (w *workerpool[T]) submitErr() error {
+	if w.isClosing() {
+		return ErrShutdown
+	}
+
+	return ErrHalted
+}
+
+// acceptErr возвращает nil, если пул ещё принимает item.
+func (w *workerpool[T]) acceptErr() error {
+	select {
+	case <-w.rejecting:
+		return w.submitErr()
+	default:
+		return nil
+	}
+}
+
+// waitRunning возвращает false, если пул не запущен, иначе дожидается, пока main получит ctx.
+func (w *workerpool[T]) waitRunning() bool {
+	if !w.IsStarted() {
+		return false
+	}
+
+	w.condr.Wait(func() bool {
+		return w.ctx != nil
+	})
+
+	return true
+}
+
+func (w *workerpool[T]) drainInput() []T {
+	var result []T
+	for {
+		select {
+		case item, ok := <-w.Input:
+			if !ok {
+				return result
+			}
+
+			result = append(result, item)
+		default:
+			return result
+		}
+	}
+}
+
+// strand запоминает item, который фоновая стадия забрала из очереди, но не успела передать дальше.
+func (w *workerpool[T]) strand(item T) {
+	w.strandedMu.Lock()
+	w.stranded = append(w.stranded, item)
+	w.strandedMu.Unlock()
+}
+
+func (w *workerpool[T]) drainQueue() []T {
+	w.strandedMu.Lock()
+	result := w.stranded
+	w.stranded = nil
+	w.strandedMu.Unlock()
+
+	if w.fair != nil {
+		result = append(result, w.fair.drain()...)
+	}
+
+	return append(result, w.drainInput()...)
+}
+
+// rejectLeftovers вызывается при остановке main: пул перестаёт принимать item,
+// а оставшиеся в очереди отклоняются, чтобы их отправители не ждали вечно.
+// При Shutdown очередь забирает и возвращает сам Shutdown.
+func (w *workerpool[T]) rejectLeftovers() {
+	w.reject()
+
+	// Дожидаемся отправителей, которые успели пройти проверку до закрытия
+	xsync.Barrier(&w.submitMu)
+
+	if w.isClosing() {
+		return
+	}
+
+	for _, item := range w.drainQueue() {
+		w.metrics.onDrop()
+		if w.onReject != nil {
+			w.onReject(item, ErrHalted)
+		}
+	}
+}
+
+// Shutdown останавливает пул: новые item отклоняются с ErrShutdown, воркеры доделывают текущие item и,
+// если drain, всё, что уже стоит в очереди. Возвращает item, которые так и не были обработаны.
+// Если ctx истёк раньше, пул прерывается, а Shutdown возвращает ошибку ctx.
+// Item, отправленные напрямую в Input, не защищены: Input нельзя использовать вместе с Shutdown.
+func (w *workerpool[T]) Shutdown(ctx context.Context, drain bool) ([]T, error) {
+	first := false
+	w.closeOnce.Do(func() {
+		first = true
+		close(w.closing)
+	})
+
+	if !first {
+		return nil, ErrShutdown
+	}
+
+	w.reject()
+
+	// Дожидаемся отправителей, которые успели пройти проверку до закрытия
+	xsync.Barrier(&w.submitMu)
+
+	if !w.waitRunning() {
+		close(w.stopIdle)
+		return w.drainQueue(), nil
+	}
+
+	var unprocessed []T
+	if !drain {
+		unprocessed = w.drainQueue()
+	}
+
+	// Воркеры завершаются, как только нечего взять, поэтому сначала дожидаемся стадий перед ними
+	for _, stage := range []chan struct{}{w.dispatched, w.limited} {
+		if stage == nil {
+			continue
+		}
+
+		select {
+		case <-stage:
+		case <-w.halted:
+		case <-ctx.Done():
+		}
+	}
+
+	close(w.stopIdle)
+
+	select {
+	case <-w.halted:
+		return append(unprocessed, w.drainQueue()...), nil
+	case <-ctx.Done():
+	}
+
+	// Забираем очередь до прерывания, чтобы воркеры не успели взять из неё item
+	unprocessed = append(unprocessed, w.drainQueue()...)
+
+	_ = w.Interrupt(ctx)
+	_ = w.WaitUntilHalted(context.Background())
+
+	return append(unprocessed, w.drainQueue()...), ctx.Err()
+}
+
+// Shutdown как у базового пула; Promise необработанных item отклоняются с ErrShutdown.
+func (w *resultWorkerpool[T, R]) Shutdown(ctx context.Context, drain bool) ([]T, error) {
+	jobs, err := w.pool.Shutdown(ctx, drain)
+
+	var result []T
+	for _, j := range jobs {
+		j.finish(std.Zero[R](), ErrShutdown)
+		result = append(result, j.item)
+	}
+
+	return result, err
+}
+
+func (w *partitionedWorkerpool[T]) backlogEmpty() bool {
+	for _, n := range w.Backlog() {
+		if n > 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Shutdown как у базового пула. С drain дожидается, пока опустеют все партиции;
+// возвращает item, оставшиеся в партициях, в порядке партиций.
+func (w *partitionedWorkerpool[T]) Shutdown(ctx context.Context, drain bool) ([]T, error) {
+	w.submitMu.Lock()
+	alreadyClosing := w.closing
+	w.closing = true
+	w.submitMu.Unlock()
+
+	if alreadyClosing {
+		return nil, ErrShutdown
+	}
+
+	if drain && w.pool.waitRunning() {
+		for !w.backlogEmpty() {
+			select {
+			case <-w.idle:
+			case <-w.pool.halted:
+			case <-ctx.Done():
+			}
+
+			if ctx.Err() != nil || w.pool.IsHalted() {
+				break
+			}
+		}
+	}
+
+	_, err := w.pool.Shutdown(ctx, false)
+
+	var result []T
+	for _, p := range w.partitions {
+		p.mu.Lock()
+		result = append(result, p.items...)
+		p.items = nil
+		p.mu.Unlock()
+	}
+
+	return result, err
+}
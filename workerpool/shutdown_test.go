@@ -0,0 +1,252 @@
+package workerpool_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SlamJam/go-libs/co"
+	"github.com/SlamJam/go-libs/workerpool"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkerpoolShutdownDrain(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	b := newBlockedPool(t, ctx, workerpool.QueueBlock)
+
+	time.AfterFunc(50*time.Millisecond, func() { close(b.release) })
+
+	unprocessed, err := b.pool.Shutdown(ctx, true)
+	assert.NoError(t, err)
+	assert.Empty(t, unprocessed)
+	assert.True(t, b.pool.IsHalted())
+	assert.Equal(t, []int{0, 1, 2}, b.takeProcessed())
+
+	_, err = b.pool.TrySubmit(3)
+	assert.ErrorIs(t, err, workerpool.ErrShutdown)
+
+	_, err = b.pool.Shutdown(ctx, true)
+	assert.ErrorIs(t, err, workerpool.ErrShutdown)
+}
+
+func TestWorkerpoolShutdownNoDrain(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	b := newBlockedPool(t, ctx, workerpool.QueueBlock)
+
+	time.AfterFunc(50*time.Millisecond, func() { close(b.release) })
+
+	unprocessed, err := b.pool.Shutdown(ctx, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, unprocessed)
+	assert.Equal(t, []int{0}, b.takeProcessed())
+}
+
+func TestWorkerpoolShutdownDeadline(t *testing.T) {
+	t.Parallel()
+
+	b := newBlockedPool(t, context.Background(), workerpool.QueueBlock)
+	defer close(b.release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	unprocessed, err := b.pool.Shutdown(ctx, true)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, []int{1, 2}, unprocessed)
+	assert.True(t, b.pool.IsHalted())
+}
+
+func TestWorkerpoolShutdownFair(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	var processed []int
+
+	pool := workerpool.New(func(ctx context.Context, i int) {
+		mu.Lock()
+		processed = append(processed, i)
+		mu.Unlock()
+	}, 2, workerpool.WithFairQueue(workerpool.FairConfig{Classes: 2}))
+
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, pool.SubmitFair(ctx, i%2, "", i))
+	}
+
+	pool.Start(ctx)
+	assert.NoError(t, pool.WaitUntilStarted(ctx))
+
+	unprocessed, err := pool.Shutdown(ctx, true)
+	assert.NoError(t, err)
+	assert.Empty(t, unprocessed)
+
+	mu.Lock()
+	assert.Len(t, processed, 10)
+	mu.Unlock()
+
+	assert.ErrorIs(t, pool.SubmitFair(ctx, 0, "", 10), workerpool.ErrShutdown)
+}
+
+func TestWorkerpoolShutdownResult(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	release := make(chan struct{})
+
+	pool := workerpool.NewWithResult(func(ctx context.Context, i int) (int, error) {
+		<-release
+		return i * 2, nil
+	}, 1, workerpool.WithQueue(2, workerpool.QueueBlock))
+
+	pool.Start(ctx)
+
+	p0 := pool.Submit(ctx, 1)
+	p1 := pool.Submit(ctx, 2)
+
+	// Дожидаемся, пока воркер возьмёт первый item
+	assert.Eventually(t, func() bool { return pool.QueueLen() == 1 }, time.Second, time.Millisecond)
+
+	time.AfterFunc(50*time.Millisecond, func() { close(release) })
+
+	unprocessed, err := pool.Shutdown(ctx, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2}, unprocessed)
+
+	v, err := p0.Poll(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, v)
+
+	_, err = p1.Poll(ctx)
+	assert.ErrorIs(t, err, workerpool.ErrShutdown)
+
+	_, err = pool.Submit(ctx, 3).Poll(ctx)
+	assert.ErrorIs(t, err, workerpool.ErrShutdown)
+}
+
+func TestPartitionedShutdownDrain(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	processed := map[string][]int{}
+
+	type item struct {
+		key string
+		n   int
+	}
+
+	pool := workerpool.NewPartitioned(func(ctx context.Context, it item) {
+		mu.Lock()
+		processed[it.key] = append(processed[it.key], it.n)
+		mu.Unlock()
+	}, func(it item) string { return it.key }, 4, 2)
+
+	pool.Start(ctx)
+	assert.NoError(t, pool.WaitUntilStarted(ctx))
+
+	for i := 0; i < 20; i++ {
+		assert.NoError(t, pool.Submit(ctx, item{key: []string{"a", "b", "c"}[i%3], n: i}))
+	}
+
+	unprocessed, err := pool.Shutdown(ctx, true)
+	assert.NoError(t, err)
+	assert.Empty(t, unprocessed)
+
+	mu.Lock()
+	total := 0
+	for _, v := range processed {
+		total += len(v)
+	}
+	mu.Unlock()
+	assert.Equal(t, 20, total)
+
+	assert.ErrorIs(t, pool.Submit(ctx, item{key: "a"}), workerpool.ErrShutdown)
+}
+
+func TestWorkerpoolInterruptRejectsQueued(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	const n = 10
+
+	started := make(chan struct{}, n)
+	pool := workerpool.NewWithResult(func(ctx context.Context, i int) (int, error) {
+		started <- struct{}{}
+		<-ctx.Done()
+		return 0, ctx.Err()
+	}, 1, workerpool.WithQueue(n, workerpool.QueueBlock))
+
+	pool.Start(ctx)
+
+	var promises []co.Promise[int]
+	for i := range n {
+		promises = append(promises, pool.Submit(ctx, i))
+	}
+
+	<-started
+	cancel()
+
+	// Каждый Promise разрешается: выполняемый item - ошибкой ctx, оставшиеся в очереди - ErrHalted
+	waitCtx, waitCancel := context.WithTimeout(context.Background(), time.Second)
+	defer waitCancel()
+
+	halted := 0
+	for _, p := range promises {
+		_, err := p.Poll(waitCtx)
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, context.DeadlineExceeded)
+
+		if errors.Is(err, workerpool.ErrHalted) {
+			halted++
+		}
+	}
+
+	assert.Equal(t, n-1, halted)
+
+	// Остановленный пул сразу отклоняет новые item
+	_, err := pool.Submit(waitCtx, n).Poll(waitCtx)
+	assert.ErrorIs(t, err, workerpool.ErrHalted)
+}
+
+func TestPartitionedInterruptKeepsBacklog(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	started := make(chan struct{}, 1)
+	pool := workerpool.NewPartitioned(func(ctx context.Context, i int) {
+		started <- struct{}{}
+		<-ctx.Done()
+	}, func(i int) string { return "key" }, 1, 1)
+
+	pool.Start(ctx)
+
+	for i := range 3 {
+		assert.NoError(t, pool.Submit(ctx, i))
+	}
+
+	<-started
+	cancel()
+	assert.NoError(t, pool.WaitUntilHalted(context.Background()))
+
+	// Остановленный пул не теряет принятые item и не принимает новые
+	assert.ErrorIs(t, pool.Submit(context.Background(), 3), workerpool.ErrHalted)
+	assert.Equal(t, []int{2}, pool.Backlog())
+
+	unprocessed, err := pool.Shutdown(context.Background(), true)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, unprocessed)
+}
@@ -0,0 +1,214 @@
+package workerpool
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	std "github.com/SlamJam/go-libs"
+	"github.com/SlamJam/go-libs/co"
+	"github.com/SlamJam/go-libs/options"
+	"github.com/pkg/errors"
+)
+
+// TimeoutError - item не уложился в отведённое время.
+type TimeoutError struct {
+	// Воркер не дождался возврата из f и был заменён новым
+	Abandoned bool
+	Cause     error
+}
+
+func (e *TimeoutError) Error() string {
+	if e.Abandoned {
+		return "workerpool job abandoned: " + e.Cause.Error()
+	}
+
+	return "workerpool job timed out: " + e.Cause.Error()
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return e.Cause
+}
+
+// WithJobTimeout ограничивает время выполнения каждого item: ctx, переданный в f, отменяется через d.
+func WithJobTimeout(d time.Duration) Opt {
+	return func(o *poolOptions) {
+		o.jobTimeout = d
+	}
+}
+
+// WithAbandonAfter бросает воркер, если f не вернулась за grace после отмены ctx item,
+// и запускает вместо него новый. Брошенная горутина продолжает работать, её результат игнорируется.
+// f выполняется в отдельной горутине, поэтому опция немного увеличивает накладные расходы.
+func WithAbandonAfter(grace time.Duration) Opt {
+	return func(o *poolOptions) {
+		o.abandonAfter = grace
+	}
+}
+
+func isTimedOut(ctx context.Context) bool {
+	return errors.Is(ctx.Err(), context.DeadlineExceeded)
+}
+
+// watchedContext отмечает, обращалась ли f к ctx. f из New ничего не возвращает,
+// поэтому без этого нельзя отличить успешный item, завершившийся позже дедлайна, от прерванного им.
+type watchedContext struct {
+	context.Context
+	watched atomic.Bool
+}
+
+func (c *watchedContext) Done() <-chan struct{} {
+	c.watched.Store(true)
+	return c.Context.Done()
+}
+
+func (c *watchedContext) Err() error {
+	c.watched.Store(true)
+	return c.Context.Err()
+}
+
+// timeoutErr возвращает TimeoutError, только если дедлайн истёк до возврата из f в returnedAt
+// и f следила за ctx, то есть могла им прерваться.
+func (c *watchedContext) timeoutErr(returnedAt time.Time) error {
+	deadline, ok := c.Context.Deadline()
+	if !ok || returnedAt.Before(deadline) || !c.watched.Load() || !isTimedOut(c.Context) {
+		return nil
+	}
+
+	return &TimeoutError{Cause: c.Context.Err()}
+}
+
+// jobContext возвращает ctx для выполнения item: ctx пула с таймаутом пула и ограничениями самого item.
+func (w *workerpool[T]) jobContext(item T) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(w.ctx)
+	cancels := []func(){cancel}
+
+	if d := w.opts.jobTimeout; d > 0 {
+		ctx, cancel = context.WithTimeout(ctx, d)
+		cancels = append(cancels, cancel)
+	}
+
+	if w.itemContext != nil {
+		if itemCtx := w.itemContext(item); itemCtx != nil {
+			if deadline, ok := itemCtx.Deadline(); ok {
+				ctx, cancel = context.WithDeadline(ctx, deadline)
+				cancels = append(cancels, cancel)
+			}
+
+			// Дедлайн item сработает сам, а отмену по Job.Cancel нужно передать
+			rootCancel := cancels[0]
+			stop := context.AfterFunc(itemCtx, func() {
+				if errors.Is(itemCtx.Err(), context.Canceled) {
+					rootCancel()
+				}
+			})
+			cancels = append(cancels, func() { stop() })
+		}
+	}
+
+	return ctx, func() {
+		for i := len(cancels) - 1; i >= 0; i-- {
+			cancels[i]()
+		}
+	}
+}
+
+// runOrAbandon выполняет run и возвращает false, если f не вернулась за abandonAfter после отмены ctx.
+func (w *workerpool[T]) runOrAbandon(ctx context.Context, run func()) bool {
+	grace := w.opts.abandonAfter
+	if grace <= 0 {
+		run()
+		return true
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		run()
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+	}
+
+	timer := time.NewTimer(grace)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+type jobOptions struct {
+	timeout time.Duration
+}
+
+type JobOpt = options.Opt[jobOptions]
+
+// JobTimeout ограничивает время от отправки item до завершения его обработки.
+func JobTimeout(d time.Duration) JobOpt {
+	return func(o *jobOptions) {
+		o.timeout = d
+	}
+}
+
+const (
+	jobQueued int32 = iota
+	jobRunning
+	jobCancelled
+)
+
+// Job - отправленный item, который можно отменить.
+type Job[R any] struct {
+	promise co.Promise[R]
+	cancel  context.CancelFunc
+	resolve func(R, error)
+	// Общее с job: jobQueued, пока воркер не взял item
+	state *atomic.Int32
+}
+
+func (j *Job[R]) Promise() co.Promise[R] {
+	return j.promise
+}
+
+// Cancel отменяет ctx item. Если item ещё в очереди, Promise сразу отклоняется с context.Canceled,
+// а воркер его пропустит. Если f уже выполняется, Promise разрешится тем, что вернёт f,
+// или TimeoutError, если воркер брошен по WithAbandonAfter: f должна сама следить за ctx.
+func (j *Job[R]) Cancel() {
+	if j.cancel == nil {
+		return
+	}
+
+	j.cancel()
+
+	if j.state.CompareAndSwap(jobQueued, jobCancelled) {
+		j.resolve(std.Zero[R](), context.Canceled)
+	}
+}
+
+// SubmitJob как Submit, но возвращает Job, через который item можно отменить.
+func (w *resultWorkerpool[T, R]) SubmitJob(ctx context.Context, item T, opts ...JobOpt) *Job[R] {
+	o := options.Create(opts...)
+
+	p, resolve := co.NewPending[R]()
+	j := job[T, R]{item: item, resolve: resolve, state: &atomic.Int32{}}
+
+	if o.timeout > 0 {
+		j.ctx, j.cancel = context.WithTimeout(context.Background(), o.timeout)
+	} else {
+		j.ctx, j.cancel = context.WithCancel(context.Background())
+	}
+
+	res, err := w.pool.SubmitContext(ctx, j)
+	p = w.promiseFor(p, res, err)
+	if err != nil || res == DroppedNewest {
+		j.cancel()
+	}
+
+	return &Job[R]{promise: p, cancel: j.cancel, resolve: resolve, state: j.state}
+}
@@ -0,0 +1,212 @@
+package workerpool_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/SlamJam/go-libs/workerpool"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkerpoolJobTimeout(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	w := workerpool.NewWithResult(func(ctx context.Context, i int) (int, error) {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	}, 1, workerpool.WithJobTimeout(20*time.Millisecond))
+
+	w.Start(ctx)
+
+	_, err := w.Submit(ctx, 1).Poll(ctx)
+
+	var timeoutErr *workerpool.TimeoutError
+	assert.ErrorAs(t, err, &timeoutErr)
+	assert.False(t, timeoutErr.Abandoned)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	assert.Eventually(t, func() bool { return w.GetStat().TimedOut == 1 }, time.Second, time.Millisecond)
+}
+
+func TestWorkerpoolJobTimeoutErrorsStream(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	const n = 50
+
+	w := workerpool.NewWithResult(func(ctx context.Context, i int) (int, error) {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	}, 4, workerpool.WithJobTimeout(time.Millisecond), workerpool.WithErrors(n))
+
+	w.Start(ctx)
+
+	for i := range n {
+		w.Submit(ctx, i)
+	}
+
+	// Ошибка каждого просроченного item доходит до Errors, хотя ctx item уже завершён
+	for range n {
+		select {
+		case e := <-w.Errors():
+			var timeoutErr *workerpool.TimeoutError
+			assert.ErrorAs(t, e.Error, &timeoutErr)
+		case <-time.After(time.Second):
+			t.Fatal("timeout error was not sent to Errors")
+		}
+	}
+}
+
+func TestWorkerpoolJobTimeoutIgnoredDeadline(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan struct{}, 2)
+
+	w := workerpool.New(func(ctx context.Context, i int) {
+		defer func() { done <- struct{}{} }()
+
+		if i == 0 {
+			// Не смотрит на ctx и успешно завершается после дедлайна
+			time.Sleep(30 * time.Millisecond)
+			return
+		}
+
+		<-ctx.Done()
+	}, 1, workerpool.WithJobTimeout(10*time.Millisecond))
+
+	w.Start(ctx)
+
+	for i := range 2 {
+		assert.NoError(t, w.Submit(ctx, i))
+		<-done
+	}
+
+	assert.Eventually(t, func() bool {
+		st := w.GetStat()
+		return st.Completed == 1 && st.TimedOut == 1
+	}, time.Second, time.Millisecond)
+}
+
+func TestWorkerpoolCancelRunningJob(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	w := workerpool.NewWithResult(func(ctx context.Context, i int) (int, error) {
+		close(started)
+		// Отмену видит, но дорабатывает до конца
+		<-release
+		return i, nil
+	}, 1)
+
+	w.Start(ctx)
+
+	j := w.SubmitJob(ctx, 7)
+	<-started
+	j.Cancel()
+
+	// Пока f выполняется, Promise не разрешён
+	pollCtx, pollCancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer pollCancel()
+
+	_, err := j.Promise().Poll(pollCtx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	close(release)
+
+	v, err := j.Promise().Poll(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, v)
+}
+
+func TestWorkerpoolSubmitJob(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	started := make(chan struct{}, 1)
+
+	w := workerpool.NewWithResult(func(ctx context.Context, i int) (int, error) {
+		started <- struct{}{}
+		<-ctx.Done()
+		return 0, ctx.Err()
+	}, 1, workerpool.WithQueue(1, workerpool.QueueBlock))
+
+	w.Start(ctx)
+
+	running := w.SubmitJob(ctx, 1)
+	<-started
+
+	queued := w.SubmitJob(ctx, 2)
+	queued.Cancel()
+
+	_, err := queued.Promise().Poll(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	running.Cancel()
+	_, err = running.Promise().Poll(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// Отменённый в очереди item не выполняется
+	select {
+	case <-started:
+		t.Fatal("cancelled job was run")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	_, err = w.SubmitJob(ctx, 3, workerpool.JobTimeout(20*time.Millisecond)).Promise().Poll(ctx)
+
+	var timeoutErr *workerpool.TimeoutError
+	assert.ErrorAs(t, err, &timeoutErr)
+}
+
+func TestWorkerpoolAbandon(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stuck := make(chan struct{})
+	defer close(stuck)
+
+	w := workerpool.NewWithResult(func(ctx context.Context, i int) (int, error) {
+		if i == 0 {
+			// Игнорирует отмену
+			<-stuck
+		}
+
+		return i, nil
+	}, 1, workerpool.WithJobTimeout(10*time.Millisecond), workerpool.WithAbandonAfter(10*time.Millisecond))
+
+	w.Start(ctx)
+
+	_, err := w.Submit(ctx, 0).Poll(ctx)
+
+	var timeoutErr *workerpool.TimeoutError
+	assert.True(t, errors.As(err, &timeoutErr))
+	assert.True(t, timeoutErr.Abandoned)
+
+	// Брошенный воркер заменён, пул продолжает работать
+	v, err := w.Submit(ctx, 1).Poll(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, v)
+
+	st := w.GetStat()
+	assert.Equal(t, uint64(1), st.Abandoned)
+	assert.Equal(t, 1, st.Workers)
+}
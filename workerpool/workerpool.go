@@ -2,12 +2,19 @@ package workerpool
 
 import (
 	"context"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	std "github.com/SlamJam/go-libs"
 	"github.com/SlamJam/go-libs/actors"
-	"github.com/SlamJam/go-libs/xgo"
+	"github.com/SlamJam/go-libs/options"
 	"github.com/SlamJam/go-libs/xsync"
+	"github.com/pkg/errors"
 )
 
+var ErrHalted = errors.New("workerpool is halted")
+
 type Workerpool interface {
 	actors.Actor
 }
@@ -17,35 +24,201 @@ type Error[T any] struct {
 	Error error
 }
 
-type Panic[T any] struct {
-	Item  T
-	Panic any
-}
-
 type workerpool[T any] struct {
 	actors.Actor
 
 	Input chan T
 	// Errors       chan Error[T]
 	Panics       chan Panic[T]
-	f            func(context.Context, T)
+	f            func(context.Context, T) error
 	ctx          context.Context
+	halted       chan struct{}
 	maxWorkers   int
 	workersCount int
-	condr        xsync.RWConditioner
+	// Закрывается и пересоздаётся при каждом изменении maxWorkers, чтобы разбудить простаивающих воркеров
+	resized chan struct{}
+	condr   xsync.RWConditioner
+	opts    poolOptions
+
+	busy        atomic.Int32
+	submitting  atomic.Int32
+	maxSubmitNs atomic.Int64
+	scaler      *autoscaler
+	fair        *fairQueue[T]
+	dispatched  chan struct{}
+	panics      panicReporter[T]
+	metrics     *poolMetrics
+	limiter     *rateLimiter[T]
+	// Откуда воркеры берут item: Input или, с ограничением темпа, выход ограничителя
+	ready   chan T
+	limited chan struct{}
+	// Item, которые фоновые стадии успели забрать из очереди, но не передали воркерам из-за прерывания
+	strandedMu sync.Mutex
+	stranded   []T
+
+	// Закрывается, когда пул перестаёт принимать item: по Shutdown или после остановки
+	rejecting  chan struct{}
+	rejectOnce sync.Once
+	// Закрывается в начале Shutdown
+	closing   chan struct{}
+	closeOnce sync.Once
+	// Закрывается, когда воркерам пора завершаться, как только очередь опустеет
+	stopIdle chan struct{}
+	// Shutdown берёт на запись, чтобы дождаться отправителей, успевших пройти проверку
+	submitMu sync.RWMutex
+	// Сериализует отправителей при QueueDropOldest
+	dropMu sync.Mutex
+
+	// Вызывается для item, вытесненных из очереди политикой DropOldest
+	onDrop func(T)
+	// Вызывается для item, оставшихся в очереди после прерывания пула
+	onReject func(T, error)
+	// Вызывается для item, воркер которого брошен по WithAbandonAfter
+	onAbandon func(T, error)
+	// Возвращает ctx самого item, если он есть
+	itemContext func(T) context.Context
+	// Вызывается для item, все попытки которого закончились паникой
+	onPanic func(Panic[T])
+}
+
+type poolOptions struct {
+	errorsCap   int
+	autoscale   *AutoscaleConfig
+	queueCap    int
+	queuePolicy QueuePolicy
+	fair        *FairConfig
+
+	panicStreamCap int
+	panicRetries   int
+	panicHandler   any
+
+	metrics         Metrics
+	durationBuckets []time.Duration
+
+	jobTimeout   time.Duration
+	abandonAfter time.Duration
+
+	rateLimit    *RateLimit
+	rateKey      any
+	keyRateLimit RateLimit
 }
 
-func New[T any](f func(context.Context, T), maxworkers int) *workerpool[T] {
+type Opt = options.Opt[poolOptions]
+
+// WithErrors включает поток ошибок размером size (см. NewWithResult).
+// Поток нужно вычитывать, иначе воркеры блокируются на отправке.
+func WithErrors(size int) Opt {
+	return func(o *poolOptions) {
+		o.errorsCap = size
+	}
+}
+
+func New[T any](f func(context.Context, T), maxworkers int, opts ...Opt) *workerpool[T] {
+	return newWorkerpool(func(ctx context.Context, item T) error {
+		watched := &watchedContext{Context: ctx}
+		f(watched, item)
+
+		return watched.timeoutErr(time.Now())
+	}, maxworkers, opts...)
+}
+
+// newWorkerpool создаёт пул, в котором ошибка f учитывается в статистике как неудачное выполнение.
+func newWorkerpool[T any](f func(context.Context, T) error, maxworkers int, opts ...Opt) *workerpool[T] {
+	var optState poolOptions
+
+	options.ApplyInto(&optState, opts...)
+
+	// Без буфера вытеснять и отбрасывать нечего
+	if optState.queuePolicy != QueueBlock {
+		std.AssertSize(optState.queueCap)
+	}
+
+	// Отправитель выполняет item сам, минуя ограничитель, поэтому темп соблюдать было бы некому
+	if optState.queuePolicy == QueueCallerRuns && (optState.rateLimit != nil || optState.rateKey != nil) {
+		panic("workerpool: WithRateLimit and WithKeyRateLimit cannot be combined with QueueCallerRuns")
+	}
+
+	// Отправители ждут места в классах очереди с приоритетами, ёмкость и политика WithQueue до них не доходят
+	if optState.fair != nil && (optState.queueCap != 0 || optState.queuePolicy != QueueBlock) {
+		panic("workerpool: WithQueue cannot be combined with WithFairQueue, use FairConfig.ClassCap")
+	}
+
 	w := &workerpool[T]{
-		Input:      make(chan T),
+		Input:      make(chan T, optState.queueCap),
 		f:          f,
+		opts:       optState,
+		halted:     make(chan struct{}),
+		rejecting:  make(chan struct{}),
+		closing:    make(chan struct{}),
+		stopIdle:   make(chan struct{}),
 		maxWorkers: maxworkers,
+		resized:    make(chan struct{}),
 		condr:      xsync.NewConditionerRW(),
 	}
 
+	w.metrics = newPoolMetrics(optState)
+	w.panics = newPanicReporter[T](optState)
+	w.Panics = w.panics.stream
+
+	if cfg := w.opts.fair; cfg != nil {
+		w.fair = newFairQueue[T](*cfg)
+		w.dispatched = make(chan struct{})
+	}
+
+	w.ready = w.Input
+	if w.limiter = newRateLimiter[T](optState); w.limiter != nil {
+		w.ready = make(chan T)
+		w.limited = make(chan struct{})
+	}
+
+	if cfg := w.opts.autoscale; cfg != nil {
+		w.scaler = newAutoscaler(*cfg, w)
+		w.maxWorkers = w.scaler.clamp(maxworkers)
+	}
+
 	w.Actor = actors.NewActor(func(ctx context.Context) error {
-		w.ctx = ctx
+		defer func() {
+			w.rejectLeftovers()
+			close(w.halted)
+		}()
+
+		w.condr.DoAndNotifyAll(func() {
+			w.ctx = ctx
+		})
 		w.maximiseWorkers()
+
+		bgCtx, cancelBg := context.WithCancel(ctx)
+		var bg sync.WaitGroup
+
+		defer func() {
+			cancelBg()
+			bg.Wait()
+		}()
+
+		if w.scaler != nil {
+			bg.Add(1)
+			go func() {
+				defer bg.Done()
+				w.scaler.run(bgCtx)
+			}()
+		}
+
+		if w.fair != nil {
+			bg.Add(1)
+			go func() {
+				defer bg.Done()
+				w.dispatch(bgCtx)
+			}()
+		}
+
+		if w.limiter != nil {
+			bg.Add(1)
+			go func() {
+				defer bg.Done()
+				w.limit(bgCtx)
+			}()
+		}
+
 		w.condr.Wait(func() bool {
 			return w.workersCount == 0
 		})
@@ -56,14 +229,36 @@ func New[T any](f func(context.Context, T), maxworkers int) *workerpool[T] {
 	return w
 }
 
+func (w *workerpool[T]) observeSubmitWait(d time.Duration) {
+	for {
+		cur := w.maxSubmitNs.Load()
+		if int64(d) <= cur || w.maxSubmitNs.CompareAndSwap(cur, int64(d)) {
+			return
+		}
+	}
+}
+
 func (w *workerpool[T]) SetWorkersCount(count int) {
 	w.condr.DoAndNotifyAll(func() {
 		w.maxWorkers = count
+		close(w.resized)
+		w.resized = make(chan struct{})
 	})
 
 	w.maximiseWorkers()
 }
 
+// WorkersCount возвращает текущее число воркеров.
+func (w *workerpool[T]) WorkersCount() int {
+	var result int
+
+	w.condr.RDo(func() {
+		result = w.workersCount
+	})
+
+	return result
+}
+
 func (w *workerpool[T]) maximiseWorkers() int {
 	var result int
 
@@ -79,7 +274,8 @@ func (w *workerpool[T]) isExcess() bool {
 }
 
 func (w *workerpool[T]) hasCapacity() bool {
-	return w.workersCount < w.maxWorkers
+	// До старта воркеры не запускаются, их запустит main
+	return w.ctx != nil && w.workersCount < w.maxWorkers
 }
 
 func (w *workerpool[T]) tryStartWorker() bool {
@@ -96,60 +292,98 @@ func (w *workerpool[T]) tryStartWorker() bool {
 		return false
 	}
 
-	// TODO: GoRecoverForever
-	go func() {
-		var needEvict bool
+	go w.work()
 
-		defer func() {
-			// Если мы тут, но не по своей воли, вероятно идёт паника.
-			// Тогда нужно сделать w.workersCount--
-			if !needEvict {
-				w.condr.DoAndNotifyAll(func() {
-					w.workersCount--
-				})
+	return true
+}
+
+// processBusy выполняет item воркером, учитывая его в Busy.
+func (w *workerpool[T]) processBusy(item T) bool {
+	w.busy.Add(1)
+	defer w.busy.Add(-1)
+
+	return w.process(item)
+}
+
+// work - цикл воркера, слот которого уже занят в workersCount.
+func (w *workerpool[T]) work() {
+	var needEvict, needReplace bool
+
+	defer func() {
+		// При вытеснении слот уже освобождён
+		if needEvict {
+			return
+		}
+
+		// После паники состояние воркера не доверяем и заменяем его новым. Замена занимает слот выбывшего
+		// под той же блокировкой: иначе main мог бы увидеть 0 воркеров и остановить пул
+		var replace bool
+
+		w.condr.DoAndNotifyAll(func() {
+			replace = needReplace && w.ctx.Err() == nil && !w.isExcess()
+			if !replace {
+				w.workersCount--
 			}
-		}()
+		})
 
-		for {
-			var isExcess bool
+		if replace {
+			go w.work()
+		}
+	}()
 
-			// "дешёвая" проверка
-			w.condr.RDo(func() {
-				isExcess = w.isExcess()
-			})
+	for {
+		var isExcess bool
+		var resized <-chan struct{}
 
-			if isExcess {
-				w.condr.DoAndNotifyAll(func() {
-					needEvict = w.isExcess()
-					if needEvict {
-						w.workersCount--
-					}
-				})
+		// "дешёвая" проверка
+		w.condr.RDo(func() {
+			isExcess = w.isExcess()
+			resized = w.resized
+		})
 
+		if isExcess {
+			w.condr.DoAndNotifyAll(func() {
+				needEvict = w.isExcess()
 				if needEvict {
-					return
+					w.workersCount--
 				}
+			})
+
+			if needEvict {
+				return
+			}
+		}
+
+		// После прерывания новые item не берём, даже если они уже в очереди
+		if w.ctx.Err() != nil {
+			return
+		}
+
+		select {
+		case item, ok := <-w.ready:
+			if !ok {
+				return
 			}
 
+			if needReplace = w.processBusy(item); needReplace {
+				return
+			}
+		case <-resized:
+		case <-w.stopIdle:
 			select {
-			case item, ok := <-w.Input:
+			case item, ok := <-w.ready:
 				if !ok {
 					return
 				}
 
-				// var err error
-				if panicObj := xgo.CatchPanic(func() { w.f(w.ctx, item) }); panicObj != nil {
-					w.Panics <- Panic[T]{Item: item, Panic: panicObj}
+				if needReplace = w.processBusy(item); needReplace {
+					return
 				}
-
-				// if err != nil {
-				// 	w.Errors <- Error[T]{Item: item, Error: err}
-				// }
-			case <-w.ctx.Done():
+			default:
 				return
 			}
+		case <-w.ctx.Done():
+			return
 		}
-	}()
-
-	return true
+	}
 }
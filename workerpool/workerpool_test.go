@@ -2,9 +2,11 @@ package workerpool_test
 
 import (
 	"context"
+	"errors"
 	"sync/atomic"
 	"testing"
 
+	"github.com/SlamJam/go-libs/co"
 	"github.com/SlamJam/go-libs/workerpool"
 	"github.com/stretchr/testify/assert"
 )
@@ -68,3 +70,52 @@ func TestWorkerpoolInterrupt(t *testing.T) {
 
 	assert.Equal(t, uint32(8), total.Load())
 }
+
+func TestWorkerpoolWithResult(t *testing.T) {
+	t.Parallel()
+
+	errOdd := errors.New("odd")
+
+	w := workerpool.NewWithResult(func(ctx context.Context, i int) (int, error) {
+		if i%2 == 1 {
+			return 0, errOdd
+		}
+
+		return i * 10, nil
+	}, 4, workerpool.WithErrors(8))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	w.Start(ctx)
+
+	var promises co.MultiPromise[int]
+	for i := range 8 {
+		promises.Append(w.Submit(ctx, i))
+	}
+
+	for i, p := range promises {
+		result, err := p.Poll(ctx)
+		if i%2 == 1 {
+			assert.ErrorIs(t, err, errOdd)
+		} else {
+			assert.NoError(t, err)
+			assert.Equal(t, i*10, result)
+		}
+	}
+
+	for range 4 {
+		e := <-w.Errors()
+		assert.Equal(t, 1, e.Item%2)
+		assert.ErrorIs(t, e.Error, errOdd)
+	}
+
+	err := w.Interrupt(ctx)
+	assert.NoError(t, err)
+
+	err = w.WaitUntilHalted(ctx)
+	assert.NoError(t, err)
+
+	err = w.Submit(ctx, 1).Await(ctx)
+	assert.ErrorIs(t, err, workerpool.ErrHalted)
+}
@@ -0,0 +1,50 @@
+//go:build goleak
+
+package xchan_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/SlamJam/go-libs/xchan"
+	"go.uber.org/goleak"
+)
+
+// Тесты не параллельные: goleak проверяет все горутины процесса.
+func TestStagesNoLeakOnCancel(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// Источник никогда не закрывается, а потребитель перестаёт читать
+	stream := make(chan int)
+	go func() {
+		for i := 0; ; i++ {
+			if xchan.PutContext(ctx, stream, i) != nil {
+				return
+			}
+		}
+	}()
+
+	double := xchan.MapContext(ctx, func(i int) int { return 2 * i })
+	parallel := xchan.ParallelContext(ctx, double(stream), func(i int) int { return i + 1 }, 4)
+	merged := xchan.FanInContext(ctx, parallel, xchan.MapContext(ctx, func(i int) int { return i })(make(chan int)))
+	batches := xchan.BatchContext[int](ctx, 100, time.Millisecond)(merged)
+
+	<-batches
+	cancel()
+}
+
+func TestQueueNoLeakOnClose(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	q := xchan.NewUnbounded[int]()
+	for i := range 10 {
+		q.In() <- i
+	}
+	close(q.In())
+
+	// VerifyNone подождёт, пока очередь отдаст item и горутины завершатся
+	xchan.DrainContext(context.Background(), q.Out())
+}
@@ -0,0 +1,175 @@
+package xchan
+
+import (
+	"sync/atomic"
+
+	"github.com/SlamJam/go-libs/options"
+)
+
+// Queue - канал с очередью в памяти между входом In и выходом Out.
+// Запись в In не ждёт потребителя. Закрытие In закрывает Out, когда потребитель прочитал оставшиеся item.
+// Очередь обслуживает горутина, которая завершается только после закрытия In.
+type Queue[T any] struct {
+	in  chan T
+	out chan T
+
+	buf        deque[T]
+	maxLen     int
+	dropOldest bool
+
+	len     atomic.Int64
+	peak    atomic.Int64
+	dropped atomic.Uint64
+}
+
+// QueueStat - статистика очереди.
+type QueueStat struct {
+	// Сколько item ждёт потребителя
+	Len int
+	// Наибольшая длина очереди за всё время
+	Peak int
+	// Item, потерянные из-за ограничения длины
+	Dropped uint64
+}
+
+type queueOptions struct {
+	maxLen int
+}
+
+type QueueOpt = options.Opt[queueOptions]
+
+// WithMaxLen ограничивает длину очереди NewUnbounded. Item, пришедшие в полную очередь, теряются.
+func WithMaxLen(n int) QueueOpt {
+	return func(o *queueOptions) {
+		o.maxLen = n
+	}
+}
+
+// NewUnbounded создаёт очередь без ограничения длины (если не задан WithMaxLen).
+func NewUnbounded[T any](opts ...QueueOpt) *Queue[T] {
+	o := options.Create(opts...)
+
+	return newQueue[T](o.maxLen, false)
+}
+
+// NewRing создаёт очередь на n item, в которой новый item вытесняет самый старый.
+func NewRing[T any](n int) *Queue[T] {
+	return newQueue[T](max(n, 1), true)
+}
+
+func newQueue[T any](maxLen int, dropOldest bool) *Queue[T] {
+	q := &Queue[T]{
+		in:         make(chan T),
+		out:        make(chan T),
+		maxLen:     maxLen,
+		dropOldest: dropOldest,
+	}
+
+	go q.run()
+
+	return q
+}
+
+func (q *Queue[T]) In() chan<- T {
+	return q.in
+}
+
+func (q *Queue[T]) Out() <-chan T {
+	return q.out
+}
+
+// Len возвращает, сколько item ждёт потребителя.
+func (q *Queue[T]) Len() int {
+	return int(q.len.Load())
+}
+
+func (q *Queue[T]) Stat() QueueStat {
+	return QueueStat{
+		Len:     q.Len(),
+		Peak:    int(q.peak.Load()),
+		Dropped: q.dropped.Load(),
+	}
+}
+
+func (q *Queue[T]) run() {
+	defer close(q.out)
+
+	in := q.in
+	for in != nil || q.buf.len() > 0 {
+		var out chan T
+		var head T
+		if q.buf.len() > 0 {
+			out = q.out
+			head = q.buf.front()
+		}
+
+		select {
+		case item, ok := <-in:
+			if !ok {
+				in = nil
+				continue
+			}
+
+			q.push(item)
+		case out <- head:
+			q.buf.popFront()
+		}
+
+		q.len.Store(int64(q.buf.len()))
+	}
+}
+
+func (q *Queue[T]) push(item T) {
+	if q.maxLen > 0 && q.buf.len() >= q.maxLen {
+		q.dropped.Add(1)
+
+		if !q.dropOldest {
+			return
+		}
+
+		q.buf.popFront()
+	}
+
+	q.buf.pushBack(item)
+
+	if n := int64(q.buf.len()); n > q.peak.Load() {
+		q.peak.Store(n)
+	}
+}
+
+// deque - очередь на кольцевом буфере, который растёт по мере надобности.
+type deque[T any] struct {
+	items []T
+	head  int
+	n     int
+}
+
+func (d *deque[T]) len() int {
+	return d.n
+}
+
+func (d *deque[T]) front() T {
+	return d.items[d.head]
+}
+
+func (d *deque[T]) pushBack(item T) {
+	if d.n == len(d.items) {
+		grown := make([]T, max(2*len(d.items), 16))
+		copied := copy(grown, d.items[d.head:])
+		copy(grown[copied:], d.items[:d.head])
+
+		d.items = grown
+		d.head = 0
+	}
+
+	d.items[(d.head+d.n)%len(d.items)] = item
+	d.n++
+}
+
+func (d *deque[T]) popFront() {
+	var zero T
+	d.items[d.head] = zero
+
+	d.head = (d.head + 1) % len(d.items)
+	d.n--
+}
@@ -0,0 +1,67 @@
+package xchan_test
+
+import (
+	"testing"
+
+	"github.com/SlamJam/go-libs/xchan"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnbounded(t *testing.T) {
+	t.Parallel()
+
+	q := xchan.NewUnbounded[int]()
+
+	// Запись не ждёт потребителя
+	for i := range 1000 {
+		q.In() <- i
+	}
+	close(q.In())
+
+	var got []int
+	for item := range xchan.Map(func(i int) int { return i * 2 })(q.Out()) {
+		got = append(got, item)
+	}
+
+	assert.Len(t, got, 1000)
+	assert.Equal(t, 1998, got[999])
+	assert.Equal(t, 1000, q.Stat().Peak)
+}
+
+func TestUnboundedMaxLen(t *testing.T) {
+	t.Parallel()
+
+	q := xchan.NewUnbounded[int](xchan.WithMaxLen(2))
+
+	for i := range 5 {
+		q.In() <- i
+	}
+	close(q.In())
+
+	var got []int
+	for item := range q.Out() {
+		got = append(got, item)
+	}
+
+	assert.Equal(t, []int{0, 1}, got)
+	assert.Equal(t, uint64(3), q.Stat().Dropped)
+}
+
+func TestRing(t *testing.T) {
+	t.Parallel()
+
+	q := xchan.NewRing[int](3)
+
+	for i := range 5 {
+		q.In() <- i
+	}
+	close(q.In())
+
+	var got []int
+	for item := range q.Out() {
+		got = append(got, item)
+	}
+
+	assert.Equal(t, []int{2, 3, 4}, got)
+	assert.Equal(t, uint64(2), q.Stat().Dropped)
+}
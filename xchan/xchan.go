@@ -38,6 +38,11 @@ func IsClosed[T any](ch <-chan T) bool {
 }
 
 func FanIn[T any](chans ...<-chan T) <-chan T {
+	return FanInContext(context.Background(), chans...)
+}
+
+// FanInContext сливает chans в один канал. После отмены ctx горутины завершаются, а результат закрывается.
+func FanInContext[T any](ctx context.Context, chans ...<-chan T) <-chan T {
 	result := make(chan T)
 
 	wg := sync.WaitGroup{}
@@ -47,9 +52,7 @@ func FanIn[T any](chans ...<-chan T) <-chan T {
 		go func() {
 			defer wg.Done()
 
-			for item := range ch {
-				result <- item
-			}
+			forward(ctx, ch, result)
 		}()
 	}
 
@@ -61,15 +64,44 @@ func FanIn[T any](chans ...<-chan T) <-chan T {
 	return result
 }
 
+// forward передаёт item из in в out, пока in не закрыт и не отменён ctx.
+func forward[T any](ctx context.Context, in <-chan T, out chan<- T) {
+	for {
+		item, ok := recv(ctx, in)
+		if !ok || PutContext(ctx, out, item) != nil {
+			return
+		}
+	}
+}
+
+// recv читает item из ch. Возвращает false, если ch закрыт или ctx отменён.
+func recv[T any](ctx context.Context, ch <-chan T) (T, bool) {
+	select {
+	case item, ok := <-ch:
+		return item, ok
+	case <-ctx.Done():
+		var zero T
+		return zero, false
+	}
+}
+
 func Map[IN, OUT any](fn func(IN) OUT) func(<-chan IN) <-chan OUT {
+	return MapContext(context.Background(), fn)
+}
+
+// MapContext как Map, но после отмены ctx горутина завершается, а результат закрывается.
+func MapContext[IN, OUT any](ctx context.Context, fn func(IN) OUT) func(<-chan IN) <-chan OUT {
 	return func(stream <-chan IN) <-chan OUT {
 		result := make(chan OUT)
 
 		go func() {
 			defer close(result)
 
-			for item := range stream {
-				result <- fn(item)
+			for {
+				item, ok := recv(ctx, stream)
+				if !ok || PutContext(ctx, result, fn(item)) != nil {
+					return
+				}
 			}
 		}()
 
@@ -78,17 +110,28 @@ func Map[IN, OUT any](fn func(IN) OUT) func(<-chan IN) <-chan OUT {
 }
 
 func Parallel[IN, OUT any](stream <-chan IN, fn func(IN) OUT, count int) <-chan OUT {
+	return ParallelContext(context.Background(), stream, fn, count)
+}
+
+// ParallelContext как Parallel, но после отмены ctx все горутины завершаются, а результат закрывается.
+func ParallelContext[IN, OUT any](ctx context.Context, stream <-chan IN, fn func(IN) OUT, count int) <-chan OUT {
 	processStreams := make([]<-chan OUT, count)
 	for i := range count {
-		processStreams[i] = Map(fn)(stream)
+		processStreams[i] = MapContext(ctx, fn)(stream)
 	}
 
-	return FanIn(processStreams...)
+	return FanInContext(ctx, processStreams...)
 }
 
 func Batch[T any](size int) func(stream <-chan T) <-chan []T {
-	return func(stream <-chan T) <-chan []T {
+	return BatchContext[T](context.Background(), size, 0)
+}
 
+// BatchContext собирает item в batch по size штук. Если interval не 0, неполный batch отправляется
+// через interval после первого item. После закрытия stream неполный batch отправляется, а после отмены ctx
+// отбрасывается, и результат сразу закрывается.
+func BatchContext[T any](ctx context.Context, size int, interval time.Duration) func(stream <-chan T) <-chan []T {
+	return func(stream <-chan T) <-chan []T {
 		result := make(chan []T)
 
 		go func() {
@@ -97,6 +140,7 @@ func Batch[T any](size int) func(stream <-chan T) <-chan []T {
 			chunk := make([]T, 0, size)
 			closed := false
 
+			var timer <-chan time.Time
 			for !closed {
 				needFlush := false
 
@@ -108,15 +152,25 @@ func Batch[T any](size int) func(stream <-chan T) <-chan []T {
 						break
 					}
 
+					if len(chunk) == 0 && interval > 0 {
+						timer = time.After(interval)
+					}
+
 					chunk = append(chunk, item)
 					needFlush = len(chunk) >= size
-					// case <-timer:
-					// needFlush = true
+				case <-timer:
+					needFlush = true
+				case <-ctx.Done():
+					return
 				}
 
 				if needFlush && (len(chunk) > 0) {
-					result <- chunk
+					if PutContext(ctx, result, chunk) != nil {
+						return
+					}
+
 					chunk = make([]T, 0, size)
+					timer = nil
 				}
 			}
 		}()
@@ -132,6 +186,17 @@ func Drain[IN any](stream <-chan IN) {
 	}()
 }
 
+// DrainContext читает stream до закрытия или отмены ctx.
+func DrainContext[IN any](ctx context.Context, stream <-chan IN) {
+	go func() {
+		for {
+			if _, ok := recv(ctx, stream); !ok {
+				return
+			}
+		}
+	}()
+}
+
 // TrySendNonBlocking пытается отправить значение в канал без блокировки.
 // Возвращает true, если значение было отправлено, и false, если канал переполнен.
 func TrySendNonBlocking[T any](ch chan T, value T) bool {
@@ -1,9 +1,12 @@
 package xchan_test
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/SlamJam/go-libs/xchan"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestExample(t *testing.T) {
@@ -17,3 +20,69 @@ func TestExample(t *testing.T) {
 		8,
 	)
 }
+
+func TestBatchContextInterval(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream := make(chan int)
+	batches := xchan.BatchContext[int](ctx, 10, 20*time.Millisecond)(stream)
+
+	stream <- 1
+	stream <- 2
+
+	// Неполный batch уходит по интервалу, не дожидаясь закрытия stream
+	select {
+	case batch := <-batches:
+		assert.Equal(t, []int{1, 2}, batch)
+	case <-time.After(time.Second):
+		t.Fatal("partial batch was not flushed")
+	}
+
+	cancel()
+
+	_, ok := <-batches
+	assert.False(t, ok)
+}
+
+func TestBatchContextCancelDropsPartial(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	stream := make(chan int)
+	batches := xchan.BatchContext[int](ctx, 10, 0)(stream)
+
+	stream <- 5
+	cancel()
+
+	// Неполный batch отброшен, результат закрыт
+	var got [][]int
+	for batch := range batches {
+		got = append(got, batch)
+	}
+
+	assert.Empty(t, got)
+}
+
+func TestParallelContextCancel(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	stream := make(chan int, 10)
+	for i := range 10 {
+		stream <- i
+	}
+
+	result := xchan.ParallelContext(ctx, stream, func(i int) int { return i }, 4)
+
+	// Потребитель читает один item и уходит
+	<-result
+	cancel()
+
+	for range result {
+	}
+}
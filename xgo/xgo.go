@@ -1,23 +1,44 @@
 package xgo
 
 import (
+	"bytes"
 	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strconv"
 )
 
 type PanicError struct {
 	Payload any
+	// Стек хранится по указателю, чтобы PanicError оставался сравнимым
+	stack *[]byte
+}
+
+// NewPanicError создаёт PanicError с сохранённым стеком горутины.
+func NewPanicError(payload any, stack []byte) PanicError {
+	return PanicError{Payload: payload, stack: &stack}
 }
 
 func (e PanicError) Error() string {
 	return fmt.Sprintf("panic was raised with payload: %+v", e.Payload)
 }
 
+// Stack возвращает стек горутины в момент паники или nil, если он не сохранён.
+func (e PanicError) Stack() []byte {
+	if e.stack == nil {
+		return nil
+	}
+
+	return *e.stack
+}
+
 var _ error = &PanicError{}
 
 func CatchPanic(f func()) (err *PanicError) {
 	defer func() {
 		if p := recover(); p != nil {
-			err = &PanicError{Payload: p}
+			pe := NewPanicError(p, debug.Stack())
+			err = &pe
 		}
 	}()
 
@@ -29,7 +50,7 @@ func CatchPanic(f func()) (err *PanicError) {
 func CatchPanicInErr(f func() error) (err error) {
 	defer func() {
 		if p := recover(); p != nil {
-			err = PanicError{Payload: p}
+			err = NewPanicError(p, debug.Stack())
 		}
 	}()
 
@@ -67,3 +88,24 @@ func GoRecoverForever(f func()) <-chan PanicError {
 
 	return out
 }
+
+// GoroutineID возвращает идентификатор текущей горутины. Предназначен только для диагностики.
+func GoroutineID() uint64 {
+	var buf [64]byte
+	return ParseGoroutineID(buf[:runtime.Stack(buf[:], false)])
+}
+
+// ParseGoroutineID извлекает идентификатор горутины из заголовка стека вида "goroutine 42 [running]:".
+func ParseGoroutineID(stack []byte) uint64 {
+	stack, ok := bytes.CutPrefix(stack, []byte("goroutine "))
+	if !ok {
+		return 0
+	}
+
+	if i := bytes.IndexByte(stack, ' '); i >= 0 {
+		stack = stack[:i]
+	}
+
+	id, _ := strconv.ParseUint(string(stack), 10, 64)
+	return id
+}
@@ -72,3 +72,12 @@ func (c *rwconditioner) RDo(do func()) {
 
 	do()
 }
+
+// Barrier дожидается, пока mu отпустят все, кто держит его на чтение.
+// Новых читателей к этому моменту должен отсекать признак закрытия, который они проверяют под RLock:
+// тогда после Barrier ни один из них уже не находится внутри и не войдёт.
+func Barrier(mu *sync.RWMutex) {
+	mu.Lock()
+	//nolint:staticcheck // пустая критическая секция - это барьер
+	mu.Unlock()
+}